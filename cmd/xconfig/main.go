@@ -0,0 +1,203 @@
+// Command xconfig checks and inspects layered configuration files before they are shipped.
+//
+// Usage:
+//
+//	xconfig validate -schema schema.json [files...]   validate the merged files against a schema
+//	xconfig print [-redact keys] [files...]           print the merged files with secrets redacted
+//	xconfig diff [-redact keys] <files-a> <files-b>   compare two comma separated file stacks
+//
+// Files are merged in order as NewLayeredConfigProvider does, "configs.json" is used when none is given.
+// Secret placeholders are not expanded, so no secret is needed to run the tool.
+//
+// The schema is generated by the application from its configuration structs with xconfig.GenerateSchema,
+// e.g. from a `go generate` step writing schema.json.
+//
+// Exit status is 0 on success, 1 when the configuration is invalid or the stacks differ and 2 on other errors.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/DreamvatLab/go/xconfig"
+	"github.com/DreamvatLab/go/xerr"
+)
+
+const usage = `Usage:
+  xconfig validate -schema schema.json [files...]
+  xconfig print [-redact keys] [files...]
+  xconfig diff [-redact keys] <files-a> <files-b>
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes a command and returns the exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	var ok bool
+	switch args[0] {
+	case "validate":
+		ok, err = validateConfig(args[1:], stdout)
+	case "print":
+		ok, err = printConfig(args[1:], stdout)
+	case "diff":
+		ok, err = diffConfigs(args[1:], stdout)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "xconfig %s: %v\n", args[0], err)
+		return 2
+	}
+	if !ok {
+		return 1
+	}
+	return 0
+}
+
+// validateConfig checks the merged files against a schema, printing every invalid value
+func validateConfig(args []string, stdout io.Writer) (bool, error) {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	schemaFile := flags.String("schema", "", "JSON Schema generated by xconfig.GenerateSchema")
+	if err := flags.Parse(args); err != nil {
+		return false, err
+	}
+	if *schemaFile == "" {
+		return false, xerr.New("-schema is required")
+	}
+
+	data, err := os.ReadFile(*schemaFile)
+	if err != nil {
+		return false, err
+	}
+	schema, err := xconfig.LoadSchema(data)
+	if err != nil {
+		return false, err
+	}
+
+	files := flags.Args()
+	config, err := load(files)
+	if err != nil {
+		return false, err
+	}
+
+	err = schema.Validate(config)
+	if configErrs, ok := err.(xconfig.ConfigErrors); ok {
+		for _, e := range configErrs {
+			fmt.Fprintln(stdout, e)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(stdout, "%s: valid\n", strings.Join(defaultFiles(files), ", "))
+	return true, nil
+}
+
+// printConfig prints the merged files with their sensitive values redacted
+func printConfig(args []string, stdout io.Writer) (bool, error) {
+	flags := flag.NewFlagSet("print", flag.ContinueOnError)
+	redact := flags.String("redact", "", "comma separated extra keys to redact")
+	if err := flags.Parse(args); err != nil {
+		return false, err
+	}
+
+	config, err := load(flags.Args())
+	if err != nil {
+		return false, err
+	}
+
+	data, err := json.MarshalIndent(xconfig.Redact(config, splitList(*redact)...), "", "  ")
+	if err != nil {
+		return false, err
+	}
+	fmt.Fprintln(stdout, string(data))
+	return true, nil
+}
+
+// diffConfigs prints the values that differ between two file stacks
+func diffConfigs(args []string, stdout io.Writer) (bool, error) {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	redact := flags.String("redact", "", "comma separated extra keys to redact")
+	if err := flags.Parse(args); err != nil {
+		return false, err
+	}
+	if flags.NArg() != 2 {
+		return false, xerr.New("expected two comma separated file stacks, e.g. configs.json,configs.dev.json configs.json,configs.prod.json")
+	}
+
+	var configs [2]xconfig.MapConfiguration
+	for i := range configs {
+		config, err := load(splitList(flags.Arg(i)))
+		if err != nil {
+			return false, err
+		}
+		configs[i] = xconfig.Redact(config, splitList(*redact)...)
+	}
+
+	changes := xconfig.Diff(configs[0], configs[1])
+	for _, change := range changes {
+		switch {
+		case change.Old == nil:
+			fmt.Fprintf(stdout, "+ %s: %s\n", change.Key, formatValue(change.New))
+		case change.New == nil:
+			fmt.Fprintf(stdout, "- %s: %s\n", change.Key, formatValue(change.Old))
+		default:
+			fmt.Fprintf(stdout, "~ %s: %s -> %s\n", change.Key, formatValue(change.Old), formatValue(change.New))
+		}
+	}
+	return len(changes) == 0, nil
+}
+
+// load merges the files in order
+func load(files []string) (xconfig.MapConfiguration, error) {
+	files = defaultFiles(files)
+	sources := make([]xconfig.IConfigSource, 0, len(files))
+	for _, file := range files {
+		sources = append(sources, xconfig.NewFileSource(file, false))
+	}
+	return xconfig.MergeSources(sources...)
+}
+
+// defaultFiles returns files, or configs.json if it is empty
+func defaultFiles(files []string) []string {
+	if len(files) == 0 {
+		return []string{"configs.json"}
+	}
+	return files
+}
+
+// splitList splits a comma separated list, ignoring empty entries
+func splitList(s string) []string {
+	var r []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			r = append(r, e)
+		}
+	}
+	return r
+}
+
+// formatValue prints a value as JSON
+func formatValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
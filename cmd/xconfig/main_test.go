@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DreamvatLab/go/xconfig"
+)
+
+type testConfig struct {
+	Name  string `config:"name" validate:"required"`
+	Port  int    `config:"port" validate:"min=1,max=65535"`
+	Redis struct {
+		Url string `config:"url"`
+	} `config:"redis"`
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// runCommand runs the command line and returns its exit status and outputs
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "configs.json", `{"name": "api", "port": 8080, "redis": {"url": "redis://u:secret@h:6379"}}`)
+	prod := writeFile(t, dir, "configs.prod.json", `{"port": 80, "debug": true}`)
+	invalid := writeFile(t, dir, "configs.invalid.json", `{"port": 70000}`)
+
+	schema, err := xconfig.GenerateSchema(&testConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	schemaFile := writeFile(t, dir, "schema.json", string(data))
+
+	t.Run("Validate", func(t *testing.T) {
+		code, stdout, _ := runCommand("validate", "-schema", schemaFile, base, prod)
+		if code != 0 || !strings.HasSuffix(stdout, ": valid\n") {
+			t.Errorf("Expected a valid configuration, got %d %q", code, stdout)
+		}
+
+		code, stdout, _ = runCommand("validate", "-schema", schemaFile, invalid)
+		if code != 1 || !strings.Contains(stdout, "name") || !strings.Contains(stdout, "port") {
+			t.Errorf("Expected the invalid values, got %d %q", code, stdout)
+		}
+
+		code, _, stderr := runCommand("validate", base)
+		if code != 2 || !strings.Contains(stderr, "-schema is required") {
+			t.Errorf("Expected a usage error, got %d %q", code, stderr)
+		}
+	})
+
+	t.Run("Print redacts secrets", func(t *testing.T) {
+		code, stdout, _ := runCommand("print", "-redact", "name", base)
+		if code != 0 {
+			t.Fatalf("Expected success, got %d", code)
+		}
+		var out map[string]interface{}
+		if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+			t.Fatalf("Expected JSON, got %q", stdout)
+		}
+		redis, _ := out["redis"].(map[string]interface{})
+		if out["name"] != xconfig.RedactedValue || redis["url"] != "redis://u:"+xconfig.RedactedValue+"@h:6379" {
+			t.Errorf("Unexpected output %v", out)
+		}
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		code, stdout, _ := runCommand("diff", base, base+","+prod)
+		expected := "+ debug: true\n~ port: 8080 -> 80\n"
+		if code != 1 || stdout != expected {
+			t.Errorf("Expected %q, got %d %q", expected, code, stdout)
+		}
+
+		if code, stdout, _ := runCommand("diff", base, base); code != 0 || stdout != "" {
+			t.Errorf("Expected no difference, got %d %q", code, stdout)
+		}
+
+		if code, _, _ := runCommand("diff", base); code != 2 {
+			t.Errorf("Expected a usage error, got %d", code)
+		}
+	})
+
+	t.Run("Unknown command", func(t *testing.T) {
+		if code, _, stderr := runCommand("lint"); code != 2 || !strings.Contains(stderr, "Usage") {
+			t.Errorf("Expected the usage, got %d %q", code, stderr)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package xconfig
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/DreamvatLab/go/xerr"
+)
+
+// ByteSize is a size in bytes that can be written in configuration as a number or a string such as "10MB"
+type ByteSize int64
+
+// Byte size units, using the binary multiples common in configuration files
+const (
+	Byte     ByteSize = 1
+	KiloByte          = 1024 * Byte
+	MegaByte          = 1024 * KiloByte
+	GigaByte          = 1024 * MegaByte
+	TeraByte          = 1024 * GigaByte
+)
+
+// _byteSizeUnits maps the accepted unit suffixes to their size
+var _byteSizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   KiloByte,
+	"kb":  KiloByte,
+	"kib": KiloByte,
+	"m":   MegaByte,
+	"mb":  MegaByte,
+	"mib": MegaByte,
+	"g":   GigaByte,
+	"gb":  GigaByte,
+	"gib": GigaByte,
+	"t":   TeraByte,
+	"tb":  TeraByte,
+	"tib": TeraByte,
+}
+
+// ParseByteSize parses a size such as "512", "64KB", "1.5 GiB" or "10mb".
+// Units are case-insensitive and always binary, so "1KB" is 1024 bytes.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(c rune) bool {
+		return (c < '0' || c > '9') && c != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	number, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	multiplier, ok := _byteSizeUnits[unit]
+	if !ok || number == "" {
+		return 0, xerr.Errorf("invalid byte size: %q", s)
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, xerr.Errorf("invalid byte size: %q", s)
+	}
+
+	size := f * float64(multiplier)
+	if size >= math.MaxInt64 {
+		return 0, xerr.Errorf("byte size overflows int64: %q", s)
+	}
+	return ByteSize(size), nil
+}
+
+// String returns the size using the largest unit that divides it exactly, e.g. "10MB"
+func (x ByteSize) String() string {
+	for _, unit := range []struct {
+		name string
+		size ByteSize
+	}{{"TB", TeraByte}, {"GB", GigaByte}, {"MB", MegaByte}, {"KB", KiloByte}} {
+		if x != 0 && x%unit.size == 0 {
+			return strconv.FormatInt(int64(x/unit.size), 10) + unit.name
+		}
+	}
+	return strconv.FormatInt(int64(x), 10) + "B"
+}
+
+// UnmarshalText allows ByteSize fields to be decoded from strings such as "10MB"
+func (x *ByteSize) UnmarshalText(text []byte) error {
+	r, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*x = r
+	return nil
+}
@@ -0,0 +1,66 @@
+package xconfig
+
+import (
+	"fmt"
+
+	"github.com/DreamvatLab/go/xerr"
+)
+
+// ErrKeyNotFound is reported by the Must* getters when a key does not exist
+var ErrKeyNotFound = xerr.New("key not found")
+
+// ConfigError describes a configuration value that is missing or cannot be converted to the requested type
+type ConfigError struct {
+	// Key is the full dotted path of the value
+	Key string
+	// Expected is the requested type
+	Expected string
+	// Actual is the type found in the configuration, empty if the key is missing
+	Actual string
+	// Err is the underlying error, if any
+	Err error
+}
+
+func (x *ConfigError) Error() string {
+	switch {
+	case x.Actual != "" && x.Err != nil:
+		return fmt.Sprintf("config key '%s': expected %s, got %s: %v", x.Key, x.Expected, x.Actual, x.Err)
+	case x.Actual != "":
+		return fmt.Sprintf("config key '%s': expected %s, got %s", x.Key, x.Expected, x.Actual)
+	case x.Err != nil:
+		return fmt.Sprintf("config key '%s': %v", x.Key, x.Err)
+	}
+	return fmt.Sprintf("config key '%s': invalid value", x.Key)
+}
+
+func (x *ConfigError) Unwrap() error {
+	return x.Err
+}
+
+// newTypeError creates a ConfigError for a value of the wrong type
+func newTypeError(key, expected string, actual interface{}) *ConfigError {
+	return &ConfigError{
+		Key:      key,
+		Expected: expected,
+		Actual:   typeName(actual),
+	}
+}
+
+// typeName returns the JSON name of the type of v, or its Go type for anything else
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64, float32, int, int64, int32:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}, MapConfiguration:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,209 @@
+package xconfig
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DreamvatLab/go/xerr"
+)
+
+// FileSource reads configuration from a file, the format is detected from the file extension
+type FileSource struct {
+	// Path is the location of the configuration file
+	Path string
+	// Optional makes a missing file contribute nothing instead of failing
+	Optional bool
+}
+
+// NewFileSource creates a new FileSource.
+//
+// path: The path to the configuration file.
+// optional: Whether a missing file should be silently ignored.
+func NewFileSource(path string, optional bool) IConfigSource {
+	return &FileSource{
+		Path:     path,
+		Optional: optional,
+	}
+}
+
+func (x *FileSource) Name() string {
+	return "file:" + x.Path
+}
+
+func (x *FileSource) Load() (map[string]interface{}, error) {
+	data, err := os.ReadFile(x.Path)
+	if err != nil {
+		if x.Optional && os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, xerr.WithStack(err)
+	}
+
+	return decodeFile(x.Path, data)
+}
+
+// EnvSource reads configuration from environment variables.
+//
+// Only variables starting with Prefix are used. The prefix is stripped, the rest of the name
+// is lower-cased and split on Separator to build nested keys, e.g. with prefix "APP_"
+// the variable APP_REDIS__ADDR sets the key "redis.addr", which lookups such as "Redis.Addr" find
+// as keys are matched case-insensitively when no key matches exactly.
+type EnvSource struct {
+	// Prefix selects the environment variables that belong to the application
+	Prefix string
+	// Separator splits a variable name into nested keys, "__" if empty
+	Separator string
+}
+
+// NewEnvSource creates a new EnvSource using "__" as the nesting separator.
+//
+// prefix: The prefix of the environment variables to read, e.g. "APP_".
+func NewEnvSource(prefix string) IConfigSource {
+	return &EnvSource{
+		Prefix:    prefix,
+		Separator: "__",
+	}
+}
+
+func (x *EnvSource) Name() string {
+	return "env:" + x.Prefix
+}
+
+func (x *EnvSource) Load() (map[string]interface{}, error) {
+	separator := x.Separator
+	if separator == "" {
+		separator = "__"
+	}
+
+	r := make(map[string]interface{})
+	for _, env := range os.Environ() {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok || !strings.HasPrefix(name, x.Prefix) {
+			continue
+		}
+
+		name = strings.ToLower(strings.TrimPrefix(name, x.Prefix))
+		if name == "" {
+			continue
+		}
+
+		setPath(r, strings.Split(name, separator), value)
+	}
+
+	return r, nil
+}
+
+// FlagSource reads configuration from command-line arguments.
+//
+// Flags are read in the "--key=value" form, keys are paths such as "--hosts.\"api.example.com\".port=80".
+// Declared flags may also use a single leading dash and the "--key value" form for Flags, or "--key" (which
+// sets "true") for Switches. Other arguments, such as positional arguments, negative numbers and the flags of
+// other parsers like "-test.v=true", are ignored and parsing stops at a bare "--".
+type FlagSource struct {
+	// Args are the command-line arguments to parse, without the program name
+	Args []string
+	// Flags are the keys that take their value from the next argument when written without "="
+	Flags []string
+	// Switches are the keys set to "true" when written without "="
+	Switches []string
+}
+
+// NewFlagSource creates a new FlagSource reading the "--key=value" flags.
+//
+// args: The arguments to parse. If not provided, os.Args[1:] will be used.
+func NewFlagSource(args ...string) IConfigSource {
+	if len(args) == 0 && len(os.Args) > 1 {
+		args = os.Args[1:]
+	}
+
+	return &FlagSource{
+		Args: args,
+	}
+}
+
+func (x *FlagSource) Name() string {
+	return "flags"
+}
+
+func (x *FlagSource) Load() (map[string]interface{}, error) {
+	r := make(map[string]interface{})
+
+	for i := 0; i < len(x.Args); i++ {
+		arg := x.Args[i]
+		if arg == "--" {
+			break
+		}
+		if !isFlag(arg) {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == "" {
+			continue
+		}
+
+		switch {
+		case hasValue && strings.HasPrefix(arg, "--"):
+			// every key is accepted in the --key=value form
+		case containsFold(x.Switches, name):
+			if !hasValue {
+				value = "true"
+			}
+		case containsFold(x.Flags, name):
+			if !hasValue {
+				if i+1 >= len(x.Args) || isFlag(x.Args[i+1]) {
+					return nil, xerr.Errorf("flag %s requires a value", arg)
+				}
+				i++
+				value = x.Args[i]
+			}
+		default:
+			continue
+		}
+
+		keys, err := pathKeys(name)
+		if err != nil {
+			return nil, err
+		}
+		setPath(r, keys, value)
+	}
+
+	return r, nil
+}
+
+// isFlag reports whether arg is a flag rather than a value, negative numbers are values
+func isFlag(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
+
+// containsFold reports whether keys holds key, ignoring case
+func containsFold(keys []string, key string) bool {
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// setPath stores value in m under the nested keys, creating intermediate maps as needed
+func setPath(m map[string]interface{}, keys []string, value interface{}) {
+	for i, key := range keys {
+		if i == len(keys)-1 {
+			m[key] = value
+			return
+		}
+
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[key] = next
+		}
+		m = next
+	}
+}
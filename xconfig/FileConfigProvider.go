@@ -0,0 +1,87 @@
+package xconfig
+
+import (
+	"encoding/json"
+
+	"github.com/DreamvatLab/go/xerr"
+)
+
+// FileConfigProvider reads configuration from a JSON, YAML, TOML or dotenv file.
+// Whatever the format, the content is normalized into the same tree JsonConfigProvider builds.
+type FileConfigProvider struct {
+	Path    string
+	RawJson []byte
+	MapConfiguration
+}
+
+// NewFileConfigProvider creates a new FileConfigProvider, detecting the format from the file extension.
+//
+// Supported extensions: .json, .yaml, .yml, .toml and .env
+//
+// Returns a new FileConfigProvider and panics if the file cannot be read or parsed,
+// use LoadFileConfigProvider to handle the error instead.
+func NewFileConfigProvider(path string) IConfigProvider {
+	r, err := LoadFileConfigProvider(path)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// LoadFileConfigProvider creates a new FileConfigProvider, detecting the format from the file extension.
+//
+// Supported extensions: .json, .yaml, .yml, .toml and .env
+//
+// Returns a new FileConfigProvider and an error if the file cannot be read or parsed.
+func LoadFileConfigProvider(path string) (IConfigProvider, error) {
+	r := &FileConfigProvider{
+		Path: path,
+	}
+
+	m, err := NewFileSource(path, false).Load()
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkSecrets(m)
+	if err != nil {
+		return nil, err
+	}
+
+	r.RawJson, err = json.Marshal(m)
+	if err != nil {
+		return nil, xerr.WithStack(err)
+	}
+	r.MapConfiguration = m
+
+	return r, nil
+}
+
+// NewYamlConfigProvider creates a new FileConfigProvider reading a YAML file.
+//
+// args[0]: The path to the YAML file to read. If not provided, "configs.yaml" will be used.
+func NewYamlConfigProvider(args ...string) IConfigProvider {
+	return NewFileConfigProvider(firstOrDefault(args, "configs.yaml"))
+}
+
+// NewTomlConfigProvider creates a new FileConfigProvider reading a TOML file.
+//
+// args[0]: The path to the TOML file to read. If not provided, "configs.toml" will be used.
+func NewTomlConfigProvider(args ...string) IConfigProvider {
+	return NewFileConfigProvider(firstOrDefault(args, "configs.toml"))
+}
+
+// NewDotenvConfigProvider creates a new FileConfigProvider reading a dotenv file.
+//
+// args[0]: The path to the dotenv file to read. If not provided, ".env" will be used.
+func NewDotenvConfigProvider(args ...string) IConfigProvider {
+	return NewFileConfigProvider(firstOrDefault(args, ".env"))
+}
+
+// firstOrDefault returns args[0], or defaultValue if args is empty
+func firstOrDefault(args []string, defaultValue string) string {
+	if len(args) == 0 {
+		return defaultValue
+	}
+	return args[0]
+}
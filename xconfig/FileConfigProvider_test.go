@@ -0,0 +1,116 @@
+package xconfig
+
+import (
+	"testing"
+)
+
+func TestNewFileConfigProvider(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"configs.json": `{
+			"name": "app",
+			"debug": true,
+			"ratio": 0.5,
+			"redis": {"addr": "localhost:6379", "db": 2},
+			"hosts": ["a", "b"],
+			"ports": [80, 443]
+		}`,
+		"configs.yaml": `
+name: app
+debug: true
+ratio: 0.5
+redis:
+  addr: localhost:6379
+  db: 2
+hosts: [a, b]
+ports:
+  - 80
+  - 443
+`,
+		"configs.toml": `
+name = "app"
+debug = true
+ratio = 0.5
+hosts = ["a", "b"]
+ports = [80, 443]
+
+[redis]
+addr = "localhost:6379"
+db = 2
+`,
+		"configs.env": `
+# comment
+NAME=app
+export DEBUG=true
+RATIO=0.5 # inline comment
+REDIS__ADDR="localhost:6379"
+redis.db=2
+HOSTS=["a", "b"]
+PORTS=[80, 443]
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := writeTempConfig(t, dir, name, content)
+			provider := NewFileConfigProvider(path)
+
+			if v := provider.GetString("name"); v != "app" {
+				t.Errorf("Expected 'app', got '%s'", v)
+			}
+			if !provider.GetBool("debug") {
+				t.Error("Expected debug to be true")
+			}
+			if v := provider.GetFloat64("ratio"); v != 0.5 {
+				t.Errorf("Expected 0.5, got %f", v)
+			}
+			if v := provider.GetString("redis.addr"); v != "localhost:6379" {
+				t.Errorf("Expected 'localhost:6379', got '%s'", v)
+			}
+			if v := provider.GetInt("redis.db"); v != 2 {
+				t.Errorf("Expected 2, got %d", v)
+			}
+			if v := provider.GetStringSlice("hosts"); len(v) != 2 || v[1] != "b" {
+				t.Errorf("Expected [a b], got %v", v)
+			}
+			if v := provider.GetIntSlice("ports"); len(v) != 2 || v[1] != 443 {
+				t.Errorf("Expected [80 443], got %v", v)
+			}
+
+			var redis struct {
+				Addr string `json:"addr"`
+				DB   int    `json:"db"`
+			}
+			if err := provider.GetStruct("redis", &redis); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if redis.Addr != "localhost:6379" || redis.DB != 2 {
+				t.Errorf("Unexpected struct %+v", redis)
+			}
+		})
+	}
+
+	t.Run("Quoted dotenv values stay strings", func(t *testing.T) {
+		path := writeTempConfig(t, dir, "quoted.env", "PORT=\"8080\"\nLITERAL='a\\nb'\nESCAPED=\"a\\nb\"\n")
+		provider := NewDotenvConfigProvider(path)
+		if v := provider.GetString("port"); v != "8080" {
+			t.Errorf("Expected '8080', got '%s'", v)
+		}
+		if v := provider.GetString("literal"); v != `a\nb` {
+			t.Errorf("Expected literal value, got '%s'", v)
+		}
+		if v := provider.GetString("escaped"); v != "a\nb" {
+			t.Errorf("Expected escaped value, got '%s'", v)
+		}
+	})
+
+	t.Run("Unsupported extension", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Expected panic for unsupported extension")
+			}
+		}()
+		NewFileConfigProvider(writeTempConfig(t, dir, "configs.ini", "a=b"))
+	})
+}
@@ -1,9 +1,14 @@
 package xconfig
 
-// IConfigProvider defines the interface for configuration providers
+import "time"
+
+// IConfigProvider defines the interface for configuration providers.
+// Keys are dotted paths such as "servers.0.host", a segment holding dots is quoted as in `hosts."api.example.com"`.
 type IConfigProvider interface {
 	// GetStruct retrieves a configuration section as a struct
 	GetStruct(key string, target interface{}) error
+	// Bind retrieves a configuration section as a struct, applying `default` tags and `validate` rules
+	Bind(key string, target interface{}) error
 	// GetString retrieves a string value from the configuration
 	GetString(key string) string
 	// GetStringDefault retrieves a string value from the configuration with a default value
@@ -20,4 +25,96 @@ type IConfigProvider interface {
 	GetStringSlice(key string) []string
 	// GetIntSlice retrieves a slice of integers from the configuration
 	GetIntSlice(key string) []int
+	// GetInt64 retrieves a 64-bit integer value from the configuration
+	GetInt64(key string) int64
+	// GetInt64Default retrieves a 64-bit integer value from the configuration with a default value
+	GetInt64Default(key string, defaultValue int64) int64
+	// GetDuration retrieves a duration such as "30s" from the configuration
+	GetDuration(key string) time.Duration
+	// GetDurationDefault retrieves a duration from the configuration with a default value
+	GetDurationDefault(key string, defaultValue time.Duration) time.Duration
+	// GetTime retrieves a time in RFC 3339 or "2006-01-02" format from the configuration
+	GetTime(key string) time.Time
+	// GetTimeDefault retrieves a time from the configuration with a default value
+	GetTimeDefault(key string, defaultValue time.Time) time.Time
+	// GetByteSize retrieves a size such as "10MB" from the configuration
+	GetByteSize(key string) ByteSize
+	// GetByteSizeDefault retrieves a size from the configuration with a default value
+	GetByteSizeDefault(key string, defaultValue ByteSize) ByteSize
+	// GetFloat64Slice retrieves a slice of float64 from the configuration
+	GetFloat64Slice(key string) []float64
+	// GetFloat64SliceDefault retrieves a slice of float64 from the configuration with a default value
+	GetFloat64SliceDefault(key string, defaultValue []float64) []float64
+	// GetStringMap retrieves a configuration section as a map
+	GetStringMap(key string) map[string]interface{}
+	// GetStringMapDefault retrieves a configuration section as a map with a default value
+	GetStringMapDefault(key string, defaultValue map[string]interface{}) map[string]interface{}
+	// GetStringMapString retrieves a configuration section of scalar values as a map of strings
+	GetStringMapString(key string) map[string]string
+	// GetStringMapStringDefault retrieves a configuration section as a map of strings with a default value
+	GetStringMapStringDefault(key string, defaultValue map[string]string) map[string]string
+	// GetMapSlice retrieves an array of configuration sections
+	GetMapSlice(key string) []MapConfiguration
+	// GetMapSliceDefault retrieves an array of configuration sections with a default value
+	GetMapSliceDefault(key string, defaultValue []MapConfiguration) []MapConfiguration
+	// Has reports whether the configuration holds a value under key
+	Has(key string) bool
+	// Query retrieves every value matching a key path holding * wildcards, e.g. "servers.*.host"
+	Query(key string) ([]interface{}, error)
+	// Keys returns the sorted keys of a configuration section, an empty key returns the top-level keys
+	Keys(key string) []string
+
+	// The Lookup getters tell a missing key from an invalid value, which the Get getters log and replace
+	// by the zero or default value
+
+	// LookupString retrieves a string value, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupString(key string) (string, bool, error)
+	// LookupBool retrieves a boolean value, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupBool(key string) (bool, bool, error)
+	// LookupFloat64 retrieves a float64 value, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupFloat64(key string) (float64, bool, error)
+	// LookupInt retrieves an integer value, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupInt(key string) (int, bool, error)
+	// LookupStringSlice retrieves a slice of strings, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupStringSlice(key string) ([]string, bool, error)
+	// LookupIntSlice retrieves a slice of integers, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupIntSlice(key string) ([]int, bool, error)
+	// LookupInt64 retrieves a 64-bit integer value, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupInt64(key string) (int64, bool, error)
+	// LookupDuration retrieves a duration, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupDuration(key string) (time.Duration, bool, error)
+	// LookupTime retrieves a time, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupTime(key string) (time.Time, bool, error)
+	// LookupByteSize retrieves a size, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupByteSize(key string) (ByteSize, bool, error)
+	// LookupFloat64Slice retrieves a slice of float64, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupFloat64Slice(key string) ([]float64, bool, error)
+	// LookupStringMap retrieves a section as a map, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupStringMap(key string) (map[string]interface{}, bool, error)
+	// LookupStringMapString retrieves a section as a map of strings, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupStringMapString(key string) (map[string]string, bool, error)
+	// LookupMapSlice retrieves an array of sections, reporting whether the key exists and a *ConfigError if the value is invalid
+	LookupMapSlice(key string) ([]MapConfiguration, bool, error)
+
+	// MustString retrieves a string value and panics with a *ConfigError if it is missing or invalid
+	MustString(key string) string
+	// MustBool retrieves a boolean value and panics with a *ConfigError if it is missing or invalid
+	MustBool(key string) bool
+	// MustFloat64 retrieves a float64 value and panics with a *ConfigError if it is missing or invalid
+	MustFloat64(key string) float64
+	// MustInt retrieves an integer value and panics with a *ConfigError if it is missing or invalid
+	MustInt(key string) int
+	// MustStringSlice retrieves a slice of strings and panics with a *ConfigError if it is missing or invalid
+	MustStringSlice(key string) []string
+	// MustIntSlice retrieves a slice of integers and panics with a *ConfigError if it is missing or invalid
+	MustIntSlice(key string) []int
 }
+
+var (
+	_ IConfigProvider = (*JsonConfigProvider)(nil)
+	_ IConfigProvider = (*FileConfigProvider)(nil)
+	_ IConfigProvider = (*LayeredConfigProvider)(nil)
+	_ IConfigProvider = (*WatchingConfigProvider)(nil)
+	_ IConfigProvider = (*RedisConfigProvider)(nil)
+	_ IConfigProvider = (*MapConfiguration)(nil)
+)
@@ -0,0 +1,10 @@
+package xconfig
+
+// IConfigSource defines a single layer of configuration that can be stacked by LayeredConfigProvider
+type IConfigSource interface {
+	// Name returns a human readable description of the source, used in error messages
+	Name() string
+	// Load reads the source and returns its configuration tree.
+	// A source that has nothing to contribute returns a nil map and no error.
+	Load() (map[string]interface{}, error)
+}
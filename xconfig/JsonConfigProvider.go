@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"os"
 
-	"github.com/DreamvatLab/go/xjson"
+	"github.com/DreamvatLab/go/xerr"
 )
 
 type JsonConfigProvider struct {
@@ -16,31 +16,41 @@ type JsonConfigProvider struct {
 //
 // args[0]: The path to the JSON file to read. If not provided, "configs.json" will be used.
 //
-// Returns a new JsonConfigProvider and an error if the creation fails.
+// Returns a new JsonConfigProvider and panics if the file cannot be read or parsed,
+// use LoadJsonConfigProvider to handle the error instead.
 func NewJsonConfigProvider(args ...string) IConfigProvider {
+	r, err := LoadJsonConfigProvider(args...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// LoadJsonConfigProvider creates a new JsonConfigProvider.
+//
+// args[0]: The path to the JSON file to read. If not provided, "configs.json" will be used.
+//
+// Returns a new JsonConfigProvider and an error if the creation fails.
+func LoadJsonConfigProvider(args ...string) (IConfigProvider, error) {
 	r := new(JsonConfigProvider)
 	r.MapConfiguration = make(MapConfiguration)
 
-	var configFile string
-	if len(args) == 0 {
-		configFile = "configs.json"
-	} else {
-		configFile = args[0]
-	}
+	configFile := firstOrDefault(args, "configs.json")
 
 	configData, err := os.ReadFile(configFile)
 	if err != nil {
-		panic(err)
+		return nil, xerr.WithStack(err)
 	}
 	r.RawJson = configData
 	err = json.Unmarshal(configData, &r.MapConfiguration)
 	if err != nil {
-		panic(err)
+		return nil, xerr.Wrapf(err, "parse %s", configFile)
 	}
 
-	return r
-}
+	err = checkSecrets(r.MapConfiguration)
+	if err != nil {
+		return nil, err
+	}
 
-func (x *JsonConfigProvider) GetStruct(key string, target interface{}) error {
-	return xjson.UnmarshalSection(x.RawJson, key, target)
+	return r, nil
 }
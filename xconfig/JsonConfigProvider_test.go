@@ -103,3 +103,30 @@ func TestJsonConfigProvider_GetStruct(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadJsonConfigProvider(t *testing.T) {
+	t.Run("Non-existent file", func(t *testing.T) {
+		provider, err := LoadJsonConfigProvider("non_existent_file.json")
+		if err == nil || provider != nil {
+			t.Errorf("Expected (nil, error), got (%v, %v)", provider, err)
+		}
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		path := writeTempConfig(t, t.TempDir(), "configs.json", `{"test": `)
+		if _, err := LoadJsonConfigProvider(path); err == nil {
+			t.Error("Expected error for invalid JSON")
+		}
+	})
+
+	t.Run("Valid file", func(t *testing.T) {
+		path := writeTempConfig(t, t.TempDir(), "configs.json", `{"test": "value"}`)
+		provider, err := LoadJsonConfigProvider(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if v := provider.MustString("test"); v != "value" {
+			t.Errorf("Expected 'value', got '%s'", v)
+		}
+	})
+}
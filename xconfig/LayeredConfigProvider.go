@@ -0,0 +1,171 @@
+package xconfig
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DreamvatLab/go/xerr"
+)
+
+// LayeredConfigProvider merges several configuration sources into a single document.
+//
+// Sources are applied in order, so a later source overrides the keys of an earlier one.
+// Nested maps are merged key by key while any other value, arrays included, is replaced.
+type LayeredConfigProvider struct {
+	RawJson []byte
+	MapConfiguration
+}
+
+// NewLayeredConfigProvider creates a new LayeredConfigProvider.
+//
+// sources: The configuration sources, from the lowest to the highest precedence.
+//
+// Returns a new LayeredConfigProvider and an error if any source fails to load.
+func NewLayeredConfigProvider(sources ...IConfigSource) (IConfigProvider, error) {
+	r := new(LayeredConfigProvider)
+
+	merged, err := loadSources(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	r.RawJson, err = json.Marshal(merged)
+	if err != nil {
+		return nil, xerr.WithStack(err)
+	}
+	r.MapConfiguration = merged
+
+	return r, nil
+}
+
+// DefaultConfigSources returns the conventional source stack, from the lowest to the highest precedence:
+//
+//   - configs.json
+//   - configs.{env}.json, skipped when env is empty or the file does not exist
+//   - environment variables starting with envPrefix, skipped when envPrefix is empty
+//   - command-line flags in the "--key=value" form, other arguments are ignored
+func DefaultConfigSources(env, envPrefix string) []IConfigSource {
+	r := []IConfigSource{NewFileSource("configs.json", false)}
+	if env != "" {
+		r = append(r, NewFileSource("configs."+env+".json", true))
+	}
+	if envPrefix != "" {
+		r = append(r, NewEnvSource(envPrefix))
+	}
+	r = append(r, NewFlagSource())
+	return r
+}
+
+// MergeSources loads every source in order and merges them into one configuration, as NewLayeredConfigProvider does.
+// Secret placeholders are neither expanded nor checked, so the result can be inspected without access to the secrets.
+func MergeSources(sources ...IConfigSource) (MapConfiguration, error) {
+	r := make(MapConfiguration)
+	for _, source := range sources {
+		m, err := source.Load()
+		if err != nil {
+			return nil, xerr.WithMessage(err, "load config source "+source.Name())
+		}
+		mergeMaps(r, m)
+	}
+	return r, nil
+}
+
+// loadSources merges the sources and checks their secret placeholders
+func loadSources(sources []IConfigSource) (MapConfiguration, error) {
+	r, err := MergeSources(sources...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkSecrets(r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// mergeMaps merges src into dst.
+//
+// Keys are matched exactly first and then case-insensitively, so "REDIS__ADDR" coming from
+// the environment overrides "Redis.Addr" from a file. A string overriding a boolean, number or
+// array is converted to the type of the value it replaces when possible.
+func mergeMaps(dst, src map[string]interface{}) {
+	// sorted so the keys of src differing only in case are merged in a stable order
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := src[key]
+		dstKey := findKey(dst, key)
+		existing, exists := dst[dstKey]
+
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := existing.(map[string]interface{}); ok {
+				mergeMaps(dstMap, srcMap)
+				continue
+			}
+			// copy so later merges never modify the source tree
+			copied := make(map[string]interface{}, len(srcMap))
+			mergeMaps(copied, srcMap)
+			value = copied
+		} else if s, ok := value.(string); ok && exists {
+			value = coerceString(s, existing)
+		}
+
+		dst[dstKey] = value
+	}
+}
+
+// findKey returns the key of m matching key, falling back to a case-insensitive match.
+// Among several case-insensitive matches, e.g. "Port" and "PORT", the lowest key in sorted order is returned.
+func findKey(m map[string]interface{}, key string) string {
+	if _, ok := m[key]; ok {
+		return key
+	}
+	r, found := key, false
+	for k := range m {
+		if strings.EqualFold(k, key) && (!found || k < r) {
+			r, found = k, true
+		}
+	}
+	return r
+}
+
+// coerceString converts s to the type of like, returning s unchanged if the conversion fails
+func coerceString(s string, like interface{}) interface{} {
+	switch like.(type) {
+	case bool:
+		if r, err := strconv.ParseBool(s); err == nil {
+			return r
+		}
+	case float64:
+		if r, err := strconv.ParseFloat(s, 64); err == nil {
+			return r
+		}
+	case []interface{}:
+		var r []interface{}
+		if err := json.Unmarshal([]byte(s), &r); err == nil {
+			return r
+		}
+		parts := strings.Split(s, ",")
+		r = make([]interface{}, 0, len(parts))
+		for _, part := range parts {
+			r = append(r, coerceString(strings.TrimSpace(part), firstOf(like.([]interface{}))))
+		}
+		return r
+	}
+	return s
+}
+
+// firstOf returns the first element of a slice, or nil if it is empty
+func firstOf(a []interface{}) interface{} {
+	if len(a) > 0 {
+		return a[0]
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package xconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestNewLayeredConfigProvider(t *testing.T) {
+	dir := t.TempDir()
+	base := writeTempConfig(t, dir, "configs.json", `{
+		"Name": "base",
+		"Debug": false,
+		"Redis": {"Addr": "localhost:6379", "DB": 0, "Password": "base"},
+		"Hosts": ["a", "b"],
+		"Ports": [80, 443]
+	}`)
+	staging := writeTempConfig(t, dir, "configs.staging.json", `{
+		"Name": "staging",
+		"Redis": {"Addr": "staging:6379"}
+	}`)
+
+	t.Setenv("XCFGTEST_REDIS__DB", "2")
+	t.Setenv("XCFGTEST_DEBUG", "true")
+	t.Setenv("XCFGTEST_PORTS", "8080,8443")
+
+	provider, err := NewLayeredConfigProvider(
+		NewFileSource(base, false),
+		NewFileSource(staging, true),
+		NewFileSource(filepath.Join(dir, "configs.missing.json"), true),
+		NewEnvSource("XCFGTEST_"),
+		&FlagSource{Args: []string{"--Redis.Password=flag", "-Hosts", `["c"]`, "positional"}, Flags: []string{"hosts"}},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Later file overrides earlier file", func(t *testing.T) {
+		if v := provider.GetString("Name"); v != "staging" {
+			t.Errorf("Expected 'staging', got '%s'", v)
+		}
+		if v := provider.GetString("Redis.Addr"); v != "staging:6379" {
+			t.Errorf("Expected 'staging:6379', got '%s'", v)
+		}
+	})
+
+	t.Run("Environment variables override files", func(t *testing.T) {
+		if v := provider.GetInt("Redis.DB"); v != 2 {
+			t.Errorf("Expected 2, got %d", v)
+		}
+		if !provider.GetBool("Debug") {
+			t.Error("Expected Debug to be true")
+		}
+		ports := provider.GetIntSlice("Ports")
+		if len(ports) != 2 || ports[0] != 8080 || ports[1] != 8443 {
+			t.Errorf("Expected [8080 8443], got %v", ports)
+		}
+	})
+
+	t.Run("Flags override everything", func(t *testing.T) {
+		if v := provider.GetString("Redis.Password"); v != "flag" {
+			t.Errorf("Expected 'flag', got '%s'", v)
+		}
+		hosts := provider.GetStringSlice("Hosts")
+		if len(hosts) != 1 || hosts[0] != "c" {
+			t.Errorf("Expected [c], got %v", hosts)
+		}
+	})
+
+	t.Run("Environment-only keys are found case-insensitively", func(t *testing.T) {
+		t.Setenv("XCFGTEST_CACHE__TTL", "30s")
+		provider, err := NewLayeredConfigProvider(NewFileSource(base, false), NewEnvSource("XCFGTEST_"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if v := provider.GetString("Cache.TTL"); v != "30s" {
+			t.Errorf("Expected '30s', got '%s'", v)
+		}
+	})
+
+	t.Run("GetStruct reads the merged document", func(t *testing.T) {
+		var redis struct {
+			Addr     string
+			DB       int
+			Password string
+		}
+		if err := provider.GetStruct("Redis", &redis); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if redis.Addr != "staging:6379" || redis.DB != 2 || redis.Password != "flag" {
+			t.Errorf("Unexpected struct %+v", redis)
+		}
+	})
+
+	t.Run("Missing required file", func(t *testing.T) {
+		_, err := NewLayeredConfigProvider(NewFileSource(filepath.Join(dir, "configs.missing.json"), false))
+		if err == nil {
+			t.Error("Expected error for missing required file")
+		}
+	})
+
+	t.Run("Unsupported file format", func(t *testing.T) {
+		path := writeTempConfig(t, dir, "configs.ini", "a=b")
+		_, err := NewLayeredConfigProvider(NewFileSource(path, false))
+		if err == nil {
+			t.Error("Expected error for unsupported file format")
+		}
+	})
+}
+
+func TestFlagSource_Load(t *testing.T) {
+	source := &FlagSource{
+		Args: []string{
+			"--a.b=1", "--c", "two", "--verbose", "positional", "-d=x", "--n", "-5", "-test.v=true", "--undeclared",
+			"--", "--ignored=1",
+		},
+		Flags:    []string{"c", "n"},
+		Switches: []string{"verbose", "d"},
+	}
+	m, err := source.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	config := MapConfiguration(m)
+	expected := map[string]string{"a.b": "1", "c": "two", "verbose": "true", "d": "x", "n": "-5"}
+	for key, value := range expected {
+		if v := config.GetString(key); v != value {
+			t.Errorf("%s: expected '%s', got '%s'", key, value, v)
+		}
+	}
+	for _, key := range []string{"positional", "test", "undeclared", "ignored"} {
+		if config.Has(key) {
+			t.Errorf("Expected %s to be ignored", key)
+		}
+	}
+
+	t.Run("Declared flag without value", func(t *testing.T) {
+		source := &FlagSource{Args: []string{"--c", "--d=x"}, Flags: []string{"c"}}
+		if _, err := source.Load(); err == nil {
+			t.Error("Expected error for a missing value")
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package xconfig
 
 import (
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestMapConfiguration_GetMap(t *testing.T) {
@@ -289,3 +291,246 @@ func TestMapConfiguration_GetIntSlice(t *testing.T) {
 		}
 	})
 }
+
+func TestMapConfiguration_Lookup(t *testing.T) {
+	config := MapConfiguration{
+		"port":    float64(8080),
+		"ratio":   0.5,
+		"enabled": "true",
+		"name":    "app",
+		"nested": map[string]interface{}{
+			"hosts": []interface{}{"a", 1},
+			"ports": []interface{}{float64(80), "443"},
+		},
+	}
+
+	t.Run("Found and valid", func(t *testing.T) {
+		port, found, err := config.LookupInt("port")
+		if port != 8080 || !found || err != nil {
+			t.Errorf("Expected (8080, true, nil), got (%d, %v, %v)", port, found, err)
+		}
+		enabled, found, err := config.LookupBool("enabled")
+		if !enabled || !found || err != nil {
+			t.Errorf("Expected (true, true, nil), got (%v, %v, %v)", enabled, found, err)
+		}
+		ports, found, err := config.LookupIntSlice("nested.ports")
+		if len(ports) != 2 || ports[1] != 443 || !found || err != nil {
+			t.Errorf("Expected ([80 443], true, nil), got (%v, %v, %v)", ports, found, err)
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, found, err := config.LookupString("nested.missing")
+		if found || err != nil {
+			t.Errorf("Expected (false, nil), got (%v, %v)", found, err)
+		}
+	})
+
+	t.Run("Wrong type", func(t *testing.T) {
+		_, found, err := config.LookupString("port")
+		if !found {
+			t.Error("Expected key to be found")
+		}
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			t.Fatalf("Expected *ConfigError, got %v", err)
+		}
+		if configErr.Key != "port" || configErr.Expected != "string" || configErr.Actual != "number" {
+			t.Errorf("Unexpected error %+v", configErr)
+		}
+	})
+
+	t.Run("Not an integer", func(t *testing.T) {
+		_, _, err := config.LookupInt("ratio")
+		if err == nil {
+			t.Error("Expected error for non-integral number")
+		}
+	})
+
+	t.Run("Wrong element type reports element path", func(t *testing.T) {
+		_, _, err := config.LookupStringSlice("nested.hosts")
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			t.Fatalf("Expected *ConfigError, got %v", err)
+		}
+		if configErr.Key != "nested.hosts.1" {
+			t.Errorf("Expected key 'nested.hosts.1', got '%s'", configErr.Key)
+		}
+		if slice := config.GetStringSlice("nested.hosts"); len(slice) != 1 || slice[0] != "a" {
+			t.Errorf("Expected the getter to skip the other elements, got %v", slice)
+		}
+	})
+}
+
+func TestMapConfiguration_LenientGetters(t *testing.T) {
+	config := MapConfiguration{
+		"ratio":   3.7,
+		"names":   []interface{}{"a", 1, "b"},
+		"ports":   []interface{}{80.9, "x", 443},
+		"weights": []interface{}{0.5, true},
+	}
+
+	if v := config.GetInt("ratio"); v != 3 {
+		t.Errorf("Expected 3, got %d", v)
+	}
+	if v := config.GetInt64("ratio"); v != 3 {
+		t.Errorf("Expected 3, got %d", v)
+	}
+	if v := config.GetStringSlice("names"); len(v) != 2 || v[1] != "b" {
+		t.Errorf("Expected [a b], got %v", v)
+	}
+	if v := config.GetIntSlice("ports"); len(v) != 2 || v[0] != 80 || v[1] != 443 {
+		t.Errorf("Expected [80 443], got %v", v)
+	}
+	if v := config.GetFloat64Slice("weights"); len(v) != 1 || v[0] != 0.5 {
+		t.Errorf("Expected [0.5], got %v", v)
+	}
+	if v := config.GetStringSlice("ratio"); v == nil || len(v) != 0 {
+		t.Errorf("Expected an empty slice, got %v", v)
+	}
+}
+
+func TestMapConfiguration_Must(t *testing.T) {
+	config := MapConfiguration{
+		"name": "app",
+		"port": "not a number",
+	}
+
+	if v := config.MustString("name"); v != "app" {
+		t.Errorf("Expected 'app', got '%s'", v)
+	}
+
+	mustPanic := func(name string, f func()) {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if _, ok := r.(*ConfigError); !ok {
+					t.Errorf("Expected *ConfigError panic, got %v", r)
+				}
+			}()
+			f()
+		})
+	}
+
+	mustPanic("Missing key", func() { config.MustString("missing") })
+	mustPanic("Invalid value", func() { config.MustInt("port") })
+}
+
+func TestMapConfiguration_TypedGetters(t *testing.T) {
+	config := MapConfiguration{
+		"timeout":  "1m30s",
+		"interval": float64(1000),
+		"started":  "2024-05-01T10:00:00Z",
+		"day":      "2024-05-01",
+		"max_body": "10MB",
+		"big":      "9007199254740993",
+		"ratios":   []interface{}{0.5, float64(1), "1.5"},
+		"labels":   map[string]interface{}{"team": "core", "replicas": float64(3), "canary": true},
+		"nested":   map[string]interface{}{"b": float64(1), "a": map[string]interface{}{"x": "y"}},
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a"},
+			map[string]interface{}{"host": "b"},
+		},
+	}
+
+	t.Run("Durations, times and sizes", func(t *testing.T) {
+		if v := config.GetDuration("timeout"); v != 90*time.Second {
+			t.Errorf("Expected 1m30s, got %v", v)
+		}
+		if v := config.GetDuration("interval"); v != 1000*time.Second {
+			t.Errorf("Expected 1000s, got %v", v)
+		}
+		if v := config.GetTime("started"); !v.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("Unexpected time %v", v)
+		}
+		if v := config.GetTime("day"); v.Year() != 2024 || v.Month() != time.May || v.Day() != 1 {
+			t.Errorf("Unexpected date %v", v)
+		}
+		if v := config.GetByteSize("max_body"); v != 10*MegaByte {
+			t.Errorf("Expected 10MB, got %v", v)
+		}
+		if v := config.GetInt64("big"); v != 9007199254740993 {
+			t.Errorf("Expected 9007199254740993, got %d", v)
+		}
+	})
+
+	t.Run("Seconds and overflows", func(t *testing.T) {
+		config := MapConfiguration{
+			"seconds":  "1.5",
+			"huge":     float64(1e12),
+			"unitless": "30x",
+			"int64":    "9223372036854775808",
+			"float":    float64(1e19),
+		}
+		if v, _, err := config.LookupDuration("seconds"); err != nil || v != 1500*time.Millisecond {
+			t.Errorf("Expected 1.5s, got %v, %v", v, err)
+		}
+		if _, _, err := config.LookupDuration("huge"); err == nil {
+			t.Error("Expected error for a duration overflow")
+		}
+		if _, _, err := config.LookupDuration("unitless"); err == nil {
+			t.Error("Expected error for an unknown unit")
+		}
+		if _, _, err := config.LookupInt64("int64"); err == nil {
+			t.Error("Expected error for an int64 overflow")
+		}
+		if _, _, err := config.LookupInt("float"); err == nil {
+			t.Error("Expected error for an int overflow")
+		}
+	})
+
+	t.Run("Collections", func(t *testing.T) {
+		if v := config.GetFloat64Slice("ratios"); len(v) != 3 || v[2] != 1.5 {
+			t.Errorf("Expected [0.5 1 1.5], got %v", v)
+		}
+		if v := config.GetStringMap("labels"); v["team"] != "core" {
+			t.Errorf("Expected team=core, got %v", v)
+		}
+		labels := config.GetStringMapString("labels")
+		if labels["replicas"] != "3" || labels["canary"] != "true" {
+			t.Errorf("Expected stringified labels, got %v", labels)
+		}
+		if v := config.GetMapSlice("servers"); len(v) != 2 || v[1]["host"] != "b" {
+			t.Errorf("Expected two servers, got %v", v)
+		}
+		if _, _, err := config.LookupStringMapString("nested"); err == nil {
+			t.Error("Expected error for nested section")
+		}
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		if v := config.GetDurationDefault("missing", time.Second); v != time.Second {
+			t.Errorf("Expected default 1s, got %v", v)
+		}
+		if v := config.GetDurationDefault("started", time.Second); v != time.Second {
+			t.Errorf("Expected default 1s for invalid value, got %v", v)
+		}
+		if v := config.GetByteSizeDefault("max_body", KiloByte); v != 10*MegaByte {
+			t.Errorf("Expected 10MB, got %v", v)
+		}
+		if v := config.GetInt64Default("missing", 7); v != 7 {
+			t.Errorf("Expected 7, got %d", v)
+		}
+		if v := config.GetStringMapStringDefault("missing", map[string]string{"a": "b"}); v["a"] != "b" {
+			t.Errorf("Expected default map, got %v", v)
+		}
+		if v := config.GetMapSliceDefault("missing", []MapConfiguration{}); v == nil {
+			t.Error("Expected default slice")
+		}
+	})
+
+	t.Run("Has and Keys", func(t *testing.T) {
+		if !config.Has("nested.a.x") || config.Has("nested.c") {
+			t.Error("Unexpected Has result")
+		}
+		if v := config.Keys("nested"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+			t.Errorf("Expected [a b], got %v", v)
+		}
+		if v := config.Keys("timeout"); v != nil {
+			t.Errorf("Expected nil keys for a scalar, got %v", v)
+		}
+		if v := config.Keys(""); len(v) != len(config) {
+			t.Errorf("Expected %d top-level keys, got %v", len(config), v)
+		}
+	})
+}
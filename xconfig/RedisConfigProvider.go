@@ -0,0 +1,291 @@
+package xconfig
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DreamvatLab/go/xbytes"
+	"github.com/DreamvatLab/go/xerr"
+	"github.com/DreamvatLab/go/xlog"
+	"github.com/DreamvatLab/go/xredis"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfigOptions holds the configuration of a RedisConfigProvider
+type RedisConfigOptions struct {
+	// Key is the Redis key holding the configuration, either a JSON string or a hash
+	Key string
+	// Channel is the pub/sub channel announcing changes, Key + ":changed" if empty
+	Channel string
+	// SnapshotFile optionally persists the last known good configuration, used when Redis is unreachable at startup
+	SnapshotFile string
+	// RefreshInterval optionally reloads the configuration periodically, in case a notification was missed
+	RefreshInterval time.Duration
+	// Timeout limits every Redis operation, 5 seconds if zero
+	Timeout time.Duration
+}
+
+// RedisConfigProvider reads configuration from Redis and refreshes it when a change is published.
+//
+// The configuration is stored under a single key, either as a JSON document in a string or as a hash
+// whose fields are key paths, e.g. HSET app:config Redis.Addr localhost:6379 Log.Level '"debug"'.
+// Hash values that are valid JSON are decoded, anything else is kept as a string.
+//
+// The configuration is cached locally, so reading a value never hits Redis. Publishing any message on the
+// change channel triggers a reload. When Redis is unreachable the last known good configuration is kept,
+// and at startup it can be read from SnapshotFile.
+type RedisConfigProvider struct {
+	configStore
+	client      redis.UniversalClient
+	ownsClient  bool
+	options     RedisConfigOptions
+	cancel      context.CancelFunc
+	done        chan struct{}
+	closeOnce   sync.Once
+	reloadMutex sync.Mutex
+}
+
+// NewRedisConfigProvider creates a new RedisConfigProvider connected with xredis.NewClient.
+//
+// Returns a new RedisConfigProvider and an error if neither Redis nor the snapshot file provide a configuration.
+func NewRedisConfigProvider(config *xredis.RedisConfig, options *RedisConfigOptions) (IWatchableConfigProvider, error) {
+	// xredis.NewClient ends the process on an empty address list
+	if config == nil || len(config.Addrs) == 0 {
+		return nil, xerr.New("redis addrs cannot be empty")
+	}
+
+	client := xredis.NewClient(config)
+	r, err := newRedisConfigProvider(client, options)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	r.ownsClient = true
+	return r, nil
+}
+
+// NewRedisConfigProviderWithClient creates a new RedisConfigProvider using an existing client.
+// The client is not closed when the provider is closed.
+//
+// Returns a new RedisConfigProvider and an error if neither Redis nor the snapshot file provide a configuration.
+func NewRedisConfigProviderWithClient(client redis.UniversalClient, options *RedisConfigOptions) (IWatchableConfigProvider, error) {
+	r, err := newRedisConfigProvider(client, options)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func newRedisConfigProvider(client redis.UniversalClient, options *RedisConfigOptions) (*RedisConfigProvider, error) {
+	if client == nil {
+		return nil, xerr.New("redis client cannot be nil")
+	}
+	if options == nil || options.Key == "" {
+		return nil, xerr.New("redis config key cannot be empty")
+	}
+
+	r := &RedisConfigProvider{
+		client:  client,
+		options: *options,
+		done:    make(chan struct{}),
+	}
+	if r.options.Channel == "" {
+		r.options.Channel = r.options.Key + ":changed"
+	}
+	if r.options.Timeout <= 0 {
+		r.options.Timeout = 5 * time.Second
+	}
+
+	err := r.Reload()
+	if err != nil {
+		snapshot, snapshotErr := r.readSnapshotFile()
+		if snapshotErr != nil {
+			return nil, xerr.JointErrors(err, snapshotErr)
+		}
+
+		xlog.Warnf("load config from redis failed, using snapshot %s: %v", r.options.SnapshotFile, err)
+		err = r.swap(snapshot)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	// wait for the subscription to be confirmed so no change published after this constructor returns is missed,
+	// if Redis is unreachable the subscription is retried in the background
+	pubsub := client.Subscribe(ctx, r.options.Channel)
+	subscribeCtx, subscribeCancel := context.WithTimeout(ctx, r.options.Timeout)
+	_, err = pubsub.Receive(subscribeCtx)
+	subscribeCancel()
+	if err != nil {
+		xlog.Warnf("subscribe to redis config channel %s failed: %v", r.options.Channel, err)
+	}
+
+	go r.listen(ctx, pubsub)
+
+	return r, nil
+}
+
+// Reload fetches the configuration from Redis, keeping the current one if fetching fails
+func (x *RedisConfigProvider) Reload() error {
+	err := x.reload()
+	x.deliver()
+	return err
+}
+
+// reload replaces the configuration, the change notifications are left to deliver
+func (x *RedisConfigProvider) reload() error {
+	x.reloadMutex.Lock()
+	defer x.reloadMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), x.options.Timeout)
+	defer cancel()
+
+	m, err := x.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = checkSecrets(m)
+	if err != nil {
+		return err
+	}
+
+	err = x.swap(m)
+	if err != nil {
+		return err
+	}
+
+	x.writeSnapshotFile()
+	return nil
+}
+
+func (x *RedisConfigProvider) Close() {
+	x.closeOnce.Do(func() {
+		if x.cancel != nil {
+			x.cancel()
+			<-x.done
+		}
+		if x.ownsClient {
+			if err := x.client.Close(); err != nil {
+				xlog.Warnf("close redis config client failed: %v", err)
+			}
+		}
+	})
+}
+
+// fetch reads the configuration key, whatever its type
+func (x *RedisConfigProvider) fetch(ctx context.Context) (MapConfiguration, error) {
+	keyType, err := x.client.Type(ctx, x.options.Key).Result()
+	if err != nil {
+		return nil, xerr.WithStack(err)
+	}
+
+	switch keyType {
+	case "string":
+		data, err := x.client.Get(ctx, x.options.Key).Bytes()
+		if err != nil {
+			return nil, xerr.WithStack(err)
+		}
+		return decodeJson(data)
+	case "hash":
+		fields, err := x.client.HGetAll(ctx, x.options.Key).Result()
+		if err != nil {
+			return nil, xerr.WithStack(err)
+		}
+
+		r := make(MapConfiguration)
+		for field, value := range fields {
+			var v interface{}
+			if json.Unmarshal(xbytes.StrToBytes(value), &v) != nil {
+				v = value
+			}
+			keys, err := pathKeys(field)
+			if err != nil {
+				return nil, err
+			}
+			setPath(r, keys, v)
+		}
+		return r, nil
+	case "none":
+		return nil, xerr.Errorf("redis config key %s does not exist", x.options.Key)
+	}
+
+	return nil, xerr.Errorf("redis config key %s has unsupported type %s", x.options.Key, keyType)
+}
+
+// listen reloads the configuration on every change notification and refresh tick until ctx is cancelled
+func (x *RedisConfigProvider) listen(ctx context.Context, pubsub *redis.PubSub) {
+	defer close(x.done)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+
+	var refresh <-chan time.Time
+	if x.options.RefreshInterval > 0 {
+		ticker := time.NewTicker(x.options.RefreshInterval)
+		defer ticker.Stop()
+		refresh = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-messages:
+			if !ok {
+				return
+			}
+		case <-refresh:
+		}
+
+		if err := x.Reload(); err != nil {
+			xlog.Errorf("reload config from redis failed: %+v", err)
+		}
+	}
+}
+
+// readSnapshotFile reads the last known good configuration from disk
+func (x *RedisConfigProvider) readSnapshotFile() (MapConfiguration, error) {
+	if x.options.SnapshotFile == "" {
+		return nil, xerr.New("no snapshot file configured")
+	}
+
+	data, err := os.ReadFile(x.options.SnapshotFile)
+	if err != nil {
+		return nil, xerr.WithStack(err)
+	}
+	return decodeJson(data)
+}
+
+// writeSnapshotFile persists the current configuration, replacing the previous snapshot atomically
+func (x *RedisConfigProvider) writeSnapshotFile() {
+	if x.options.SnapshotFile == "" {
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(x.options.SnapshotFile), filepath.Base(x.options.SnapshotFile)+".*")
+	if err == nil {
+		_, err = tmp.Write(x.snapshot().RawJson)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), x.options.SnapshotFile)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}
+
+	if err != nil {
+		xlog.Warnf("write config snapshot %s failed: %v", x.options.SnapshotFile, err)
+	}
+}
@@ -0,0 +1,127 @@
+package xconfig
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DreamvatLab/go/xlog"
+	"github.com/DreamvatLab/go/xredis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisConfigProvider(t *testing.T) {
+	mr := miniredis.RunT(t)
+	addr := mr.Addr()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+
+	snapshotFile := filepath.Join(t.TempDir(), "snapshot.json")
+	mr.Set("app:config", `{"Log": {"Level": "info"}, "Name": "app"}`)
+
+	provider, err := NewRedisConfigProviderWithClient(client, &RedisConfigOptions{
+		Key:          "app:config",
+		SnapshotFile: snapshotFile,
+		Timeout:      time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer provider.Close()
+
+	if v := provider.GetString("Log.Level"); v != "info" {
+		t.Fatalf("Expected 'info', got '%s'", v)
+	}
+
+	changes := make(chan [2]interface{}, 1)
+	provider.OnChange("Log.Level", func(oldValue, newValue interface{}) {
+		changes <- [2]interface{}{oldValue, newValue}
+	})
+
+	t.Run("Published change is picked up", func(t *testing.T) {
+		mr.Set("app:config", `{"Log": {"Level": "debug"}, "Name": "app"}`)
+		mr.Publish("app:config:changed", "1")
+
+		select {
+		case change := <-changes:
+			if change[0] != "info" || change[1] != "debug" {
+				t.Errorf("Expected info -> debug, got %v -> %v", change[0], change[1])
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for change notification")
+		}
+	})
+
+	t.Run("Hash fields are dotted keys", func(t *testing.T) {
+		mr.Del("app:config")
+		mr.HSet("app:config", "Log.Level", `"warn"`, "Redis.Addr", "localhost:6379", "Redis.DB", "2")
+		if err := provider.Reload(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if v := provider.GetString("Log.Level"); v != "warn" {
+			t.Errorf("Expected 'warn', got '%s'", v)
+		}
+		if v := provider.GetString("Redis.Addr"); v != "localhost:6379" {
+			t.Errorf("Expected 'localhost:6379', got '%s'", v)
+		}
+		if v := provider.GetInt("Redis.DB"); v != 2 {
+			t.Errorf("Expected 2, got %d", v)
+		}
+		<-changes
+	})
+
+	t.Run("Unreachable redis keeps last good config", func(t *testing.T) {
+		mr.Close()
+		if err := provider.Reload(); err == nil {
+			t.Error("Expected error for unreachable redis")
+		}
+		if v := provider.GetString("Log.Level"); v != "warn" {
+			t.Errorf("Expected 'warn', got '%s'", v)
+		}
+	})
+
+	t.Run("Snapshot is used when redis is unreachable at startup", func(t *testing.T) {
+		fallback, err := NewRedisConfigProvider(&xredis.RedisConfig{Addrs: []string{addr}}, &RedisConfigOptions{
+			Key:          "app:config",
+			SnapshotFile: snapshotFile,
+			Timeout:      100 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer fallback.Close()
+
+		if v := fallback.GetString("Redis.Addr"); v != "localhost:6379" {
+			t.Errorf("Expected 'localhost:6379', got '%s'", v)
+		}
+	})
+
+	t.Run("Missing key without snapshot", func(t *testing.T) {
+		_, err := NewRedisConfigProvider(&xredis.RedisConfig{Addrs: []string{addr}}, &RedisConfigOptions{
+			Key:     "app:config",
+			Timeout: 100 * time.Millisecond,
+		})
+		if err == nil {
+			t.Error("Expected error without redis and snapshot")
+		}
+	})
+
+	t.Run("Invalid redis config", func(t *testing.T) {
+		exited := false
+		defer xlog.SetExitFunc(xlog.SetExitFunc(func(int) { exited = true }))
+		if _, err := NewRedisConfigProvider(&xredis.RedisConfig{}, &RedisConfigOptions{Key: "app:config"}); err == nil {
+			t.Error("Expected error without redis address")
+		}
+		if _, err := NewRedisConfigProvider(nil, &RedisConfigOptions{Key: "app:config"}); err == nil {
+			t.Error("Expected error without redis config")
+		}
+		if exited {
+			t.Error("Expected an error instead of a fatal exit")
+		}
+		if _, err := NewRedisConfigProviderWithClient(nil, &RedisConfigOptions{Key: "app:config"}); err == nil {
+			t.Error("Expected error for a nil client")
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package xconfig
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// IWatchableConfigProvider is a configuration provider whose values can change at runtime
+type IWatchableConfigProvider interface {
+	IConfigProvider
+	// OnChange registers a callback invoked after a reload changed the value under key.
+	// An empty key subscribes to any change.
+	OnChange(key string, callback func(oldValue, newValue interface{}))
+	// Reload loads the configuration again, keeping the current one if loading fails
+	Reload() error
+	// Close stops watching for changes
+	Close()
+}
+
+// fileStamp identifies a version of a watched file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// WatchingConfigProvider reloads its configuration sources whenever one of the underlying files changes.
+//
+// Files are polled at a fixed interval. After a change the sources are loaded and merged again and
+// the new configuration replaces the old one atomically, so readers never see a partial update.
+// If loading fails the error is logged and the last good configuration stays in place.
+//
+// Example of updating the log level live:
+//
+//	provider.OnChange("Log.Level", func(oldValue, newValue interface{}) {
+//		config := logger.GetConfig()
+//		config.Level = xconv.ToString(newValue)
+//		logger.SetConfig(config)
+//	})
+type WatchingConfigProvider struct {
+	configStore
+	sources     []IConfigSource
+	interval    time.Duration
+	stamps      map[string]fileStamp
+	stop        chan struct{}
+	stopOnce    sync.Once
+	reloadMutex sync.Mutex
+}
+
+// NewWatchingConfigProvider creates a new WatchingConfigProvider and starts watching its files.
+//
+// interval: How often the files are checked for changes. If <= 0, 5 seconds will be used.
+// sources: The configuration sources, from the lowest to the highest precedence.
+//
+// Returns a new WatchingConfigProvider and an error if the initial load fails.
+func NewWatchingConfigProvider(interval time.Duration, sources ...IConfigSource) (IWatchableConfigProvider, error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	r := &WatchingConfigProvider{
+		sources:  sources,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+
+	r.stamps = r.readStamps()
+	err := r.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	go r.watch()
+
+	return r, nil
+}
+
+// Reload loads and merges the sources again, keeping the current configuration if loading fails
+func (x *WatchingConfigProvider) Reload() error {
+	err := x.reload()
+	x.deliver()
+	return err
+}
+
+// reload replaces the configuration, the change notifications are left to deliver
+func (x *WatchingConfigProvider) reload() error {
+	x.reloadMutex.Lock()
+	defer x.reloadMutex.Unlock()
+
+	m, err := loadSources(x.sources)
+	if err != nil {
+		return err
+	}
+	return x.swap(m)
+}
+
+func (x *WatchingConfigProvider) Close() {
+	x.stopOnce.Do(func() {
+		close(x.stop)
+	})
+}
+
+// watch polls the watched files until Close is called
+func (x *WatchingConfigProvider) watch() {
+	ticker := time.NewTicker(x.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-x.stop:
+			return
+		case <-ticker.C:
+			stamps := x.readStamps()
+			if !x.changed(stamps) {
+				continue
+			}
+
+			// a failed reload keeps the previous stamps, so it is retried at the next tick
+			if err := x.Reload(); err != nil {
+				xlog.Errorf("reload config failed: %+v", err)
+				continue
+			}
+			x.stamps = stamps
+		}
+	}
+}
+
+// readStamps returns the current stamp of every file source, a missing file has a zero stamp
+func (x *WatchingConfigProvider) readStamps() map[string]fileStamp {
+	r := make(map[string]fileStamp, len(x.sources))
+	for _, source := range x.sources {
+		fileSource, ok := source.(*FileSource)
+		if !ok {
+			continue
+		}
+
+		var stamp fileStamp
+		if info, err := os.Stat(fileSource.Path); err == nil {
+			stamp = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		r[fileSource.Path] = stamp
+	}
+	return r
+}
+
+// changed reports whether stamps differ from the stamps seen at the last reload
+func (x *WatchingConfigProvider) changed(stamps map[string]fileStamp) bool {
+	for path, stamp := range stamps {
+		if old, ok := x.stamps[path]; !ok || !old.modTime.Equal(stamp.modTime) || old.size != stamp.size {
+			return true
+		}
+	}
+	return false
+}
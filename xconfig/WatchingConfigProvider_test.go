@@ -0,0 +1,162 @@
+package xconfig
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchingConfigProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempConfig(t, dir, "configs.json", `{"Log": {"Level": "info"}, "Name": "app"}`)
+
+	provider, err := NewWatchingConfigProvider(10*time.Millisecond, NewFileSource(path, false))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer provider.Close()
+
+	if v := provider.GetString("Log.Level"); v != "info" {
+		t.Fatalf("Expected 'info', got '%s'", v)
+	}
+
+	changes := make(chan [2]interface{}, 1)
+	provider.OnChange("Log.Level", func(oldValue, newValue interface{}) {
+		changes <- [2]interface{}{oldValue, newValue}
+	})
+	provider.OnChange("Name", func(oldValue, newValue interface{}) {
+		t.Errorf("Unexpected change of unchanged key: %v -> %v", oldValue, newValue)
+	})
+
+	t.Run("File change is picked up", func(t *testing.T) {
+		writeTempConfig(t, dir, "configs.json", `{"Log": {"Level": "debug"}, "Name": "app"}`)
+		future := time.Now().Add(time.Second)
+		if err := os.Chtimes(path, future, future); err != nil {
+			t.Fatalf("Failed to touch config file: %v", err)
+		}
+
+		select {
+		case change := <-changes:
+			if change[0] != "info" || change[1] != "debug" {
+				t.Errorf("Expected info -> debug, got %v -> %v", change[0], change[1])
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for change notification")
+		}
+
+		if v := provider.GetString("Log.Level"); v != "debug" {
+			t.Errorf("Expected 'debug', got '%s'", v)
+		}
+
+		var log struct{ Level string }
+		if err := provider.GetStruct("Log", &log); err != nil || log.Level != "debug" {
+			t.Errorf("Expected struct with 'debug', got %+v, %v", log, err)
+		}
+	})
+
+	t.Run("Broken file keeps last good config", func(t *testing.T) {
+		writeTempConfig(t, dir, "configs.json", `{"Log": `)
+		if err := provider.Reload(); err == nil {
+			t.Error("Expected error for broken file")
+		}
+		if v := provider.GetString("Log.Level"); v != "debug" {
+			t.Errorf("Expected 'debug', got '%s'", v)
+		}
+	})
+
+	t.Run("Failed reload is retried", func(t *testing.T) {
+		// same size and time as the broken file, only a retry of the failed reload can pick it up
+		good := `{"Log": {"Level": "warn"}, "Name": "app"}`
+		writeTempConfig(t, dir, "configs.json", good[:len(good)-1]+" ")
+		stamp := time.Now().Add(2 * time.Second)
+		if err := os.Chtimes(path, stamp, stamp); err != nil {
+			t.Fatalf("Failed to touch config file: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		writeTempConfig(t, dir, "configs.json", good)
+		if err := os.Chtimes(path, stamp, stamp); err != nil {
+			t.Fatalf("Failed to touch config file: %v", err)
+		}
+
+		select {
+		case change := <-changes:
+			if change[1] != "warn" {
+				t.Errorf("Expected warn, got %v", change[1])
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for the retried reload")
+		}
+	})
+}
+
+func TestConfigStoreCallbacks(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempConfig(t, dir, "configs.json", `{"Name": "app"}`)
+
+	provider, err := NewWatchingConfigProvider(time.Hour, NewFileSource(path, false))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer provider.Close()
+
+	// a callback subscribing again or reloading must not deadlock on the store
+	done := make(chan struct{})
+	provider.OnChange("Name", func(oldValue, newValue interface{}) {
+		provider.OnChange("Other", func(oldValue, newValue interface{}) {})
+		if err := provider.Reload(); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		close(done)
+	})
+
+	writeTempConfig(t, dir, "configs.json", `{"Name": "renamed"}`)
+	go func() {
+		_ = provider.Reload()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out, the callback deadlocked")
+	}
+}
+
+func TestWatchingConfigProvider_ConcurrentReloads(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempConfig(t, dir, "configs.json", `{"Name": "app"}`)
+
+	provider, err := NewWatchingConfigProvider(time.Hour, NewFileSource(path, false))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer provider.Close()
+
+	var mu sync.Mutex
+	var changes []interface{}
+	provider.OnChange("Name", func(oldValue, newValue interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, newValue)
+	})
+
+	writeTempConfig(t, dir, "configs.json", `{"Name": "renamed"}`)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := provider.Reload(); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 1 || changes[0] != "renamed" {
+		t.Errorf("Expected a single change to 'renamed', got %v", changes)
+	}
+}
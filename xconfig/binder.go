@@ -0,0 +1,423 @@
+package xconfig
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DreamvatLab/go/xerr"
+)
+
+// ConfigErrors aggregates every invalid value found while binding a configuration section
+type ConfigErrors []*ConfigError
+
+func (x ConfigErrors) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d invalid config value(s):", len(x)))
+	for _, err := range x {
+		sb.WriteString("\n  ")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+var (
+	_durationType    = reflect.TypeOf(time.Duration(0))
+	_byteSizeType    = reflect.TypeOf(ByteSize(0))
+	_timeType        = reflect.TypeOf(time.Time{})
+	_unmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bind copies the configuration section under key into the struct target points to.
+// An empty key binds the whole configuration.
+//
+// Fields are matched by the name in their `config` tag, then their `json` tag, then the field name,
+// falling back to a case-insensitive match. A tag name of "-" skips the field. Supported tags:
+//
+//	config:"name"                   name of the key holding the field
+//	default:"30s"                   value used when the key is missing
+//	validate:"required,min=1,max=9" rules checked after binding
+//
+// Validation rules: "required" fails on a missing key without default, "min"/"max" bound numbers,
+// durations and sizes or the length of strings, slices and maps, and "oneof=a b c" restricts the
+// value to a space separated list. time.Duration fields accept strings such as "30s" or a number
+// of seconds, ByteSize fields accept strings such as "10MB" or a number of bytes and time.Time fields
+// accept the values read by GetTime.
+//
+// Binding does not stop at the first problem, the returned ConfigErrors lists every invalid field.
+func (x *MapConfiguration) Bind(key string, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return xerr.Errorf("bind target must be a non-nil pointer to a struct, got %T", target)
+	}
+
+	var section map[string]interface{}
+	node, err := lookupValue(key, *x)
+	if err != nil {
+		return err
+	}
+	if node != nil {
+		section, err = toMap(node)
+		if err != nil {
+			return newTypeError(key, "object", node)
+		}
+	}
+
+	var errs ConfigErrors
+	bindStruct(key, section, v.Elem(), &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bindStruct binds the fields of the struct v from m, appending any problem to errs
+func bindStruct(path string, m map[string]interface{}, v reflect.Value, errs *ConfigErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if field.Anonymous && name == field.Name && indirectType(field.Type).Kind() == reflect.Struct {
+			// embedded structs share the keys of their parent
+			bindStruct(path, m, allocate(fieldValue), errs)
+			continue
+		}
+
+		fieldPath := joinPath(path, name)
+		raw, found := m[findKey(m, name)]
+		if raw == nil {
+			found = false
+		}
+
+		defaultValue, hasDefault := field.Tag.Lookup("default")
+		if !found && hasDefault {
+			raw, found = defaultValue, true
+		}
+
+		rules := parseRules(field.Tag.Get("validate"))
+		if !found {
+			if _, required := rules["required"]; required {
+				*errs = append(*errs, &ConfigError{Key: fieldPath, Expected: field.Type.String(), Err: xerr.New("is required")})
+				continue
+			}
+			if indirectType(field.Type).Kind() == reflect.Struct && !isLeafType(indirectType(field.Type)) && field.Type.Kind() != reflect.Ptr {
+				// still walk nested structs so their defaults and rules apply
+				bindStruct(fieldPath, nil, fieldValue, errs)
+			}
+			continue
+		}
+
+		if err := setValue(fieldPath, raw, fieldValue, errs); err != nil {
+			*errs = append(*errs, err)
+			continue
+		}
+
+		if err := validateValue(fieldPath, fieldValue, rules); err != nil {
+			*errs = append(*errs, err)
+		}
+	}
+}
+
+// fieldName returns the configuration key of a struct field
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"config", "json"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// setValue converts raw and stores it in v, nested structs report their own errors to errs
+func setValue(path string, raw interface{}, v reflect.Value, errs *ConfigErrors) *ConfigError {
+	t := v.Type()
+
+	if t.Kind() == reflect.Ptr {
+		return setValue(path, raw, allocate(v), errs)
+	}
+
+	if s, ok := raw.(string); ok && reflect.PointerTo(t).Implements(_unmarshalerType) && t != _timeType {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return newConvertError(path, t.String(), raw, err)
+		}
+		return nil
+	}
+
+	switch t {
+	case _durationType:
+		d, err := toDuration(raw)
+		if err != nil {
+			return newConvertError(path, "duration", raw, err)
+		}
+		v.SetInt(int64(d))
+		return nil
+	case _byteSizeType:
+		size, err := toByteSize(raw)
+		if err != nil {
+			return newConvertError(path, "byte size", raw, err)
+		}
+		v.SetInt(int64(size))
+		return nil
+	case _timeType:
+		r, err := toTime(raw)
+		if err != nil {
+			return newConvertError(path, "time", raw, err)
+		}
+		v.Set(reflect.ValueOf(r))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		switch val := raw.(type) {
+		case string:
+			v.SetString(val)
+		case float64, bool:
+			v.SetString(fmt.Sprint(val))
+		default:
+			return newTypeError(path, "string", raw)
+		}
+	case reflect.Bool:
+		r, err := toBool(raw)
+		if err != nil {
+			return newConvertError(path, "bool", raw, err)
+		}
+		v.SetBool(r)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r, err := toInt(raw)
+		if err != nil {
+			return newConvertError(path, t.String(), raw, err)
+		}
+		if v.OverflowInt(int64(r)) {
+			return &ConfigError{Key: path, Expected: t.String(), Actual: "number", Err: xerr.Errorf("%d overflows %s", r, t)}
+		}
+		v.SetInt(int64(r))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		r, err := toInt(raw)
+		if err != nil {
+			return newConvertError(path, t.String(), raw, err)
+		}
+		if r < 0 || v.OverflowUint(uint64(r)) {
+			return &ConfigError{Key: path, Expected: t.String(), Actual: "number", Err: xerr.Errorf("%d overflows %s", r, t)}
+		}
+		v.SetUint(uint64(r))
+	case reflect.Float32, reflect.Float64:
+		r, err := toFloat64(raw)
+		if err != nil {
+			return newConvertError(path, t.String(), raw, err)
+		}
+		v.SetFloat(r)
+	case reflect.Slice:
+		var items []interface{}
+		switch val := raw.(type) {
+		case []interface{}:
+			items = val
+		case string:
+			// comma separated lists, typically coming from defaults or environment variables
+			for _, item := range strings.Split(val, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		default:
+			return newTypeError(path, "array", raw)
+		}
+
+		slice := reflect.MakeSlice(t, len(items), len(items))
+		var firstErr *ConfigError
+		for i, item := range items {
+			if err := setValue(path+"."+strconv.Itoa(i), item, slice.Index(i), errs); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if firstErr != nil {
+			return firstErr
+		}
+		v.Set(slice)
+	case reflect.Map:
+		m, err := toMap(raw)
+		if err != nil || t.Key().Kind() != reflect.String {
+			return newTypeError(path, "object", raw)
+		}
+		r := reflect.MakeMapWithSize(t, len(m))
+		for key, item := range m {
+			elem := reflect.New(t.Elem()).Elem()
+			if err := setValue(path+"."+key, item, elem, errs); err != nil {
+				return err
+			}
+			r.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+		v.Set(r)
+	case reflect.Struct:
+		m, err := toMap(raw)
+		if err != nil {
+			return newTypeError(path, "object", raw)
+		}
+		bindStruct(path, m, v, errs)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(raw))
+	default:
+		return &ConfigError{Key: path, Expected: t.String(), Actual: typeName(raw), Err: xerr.New("unsupported field type")}
+	}
+
+	return nil
+}
+
+// validateValue checks the min, max and oneof rules against the bound value
+func validateValue(path string, v reflect.Value, rules map[string]string) *ConfigError {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if oneOf, ok := rules["oneof"]; ok {
+		s := fmt.Sprint(v.Interface())
+		allowed := strings.Fields(oneOf)
+		if !contains(allowed, s) {
+			return &ConfigError{Key: path, Expected: "one of [" + strings.Join(allowed, " ") + "]", Err: xerr.Errorf("got %q", s)}
+		}
+	}
+
+	for _, rule := range []string{"min", "max"} {
+		limit, ok := rules[rule]
+		if !ok {
+			continue
+		}
+
+		actual, bound, err := measure(v, limit)
+		if err != nil {
+			return &ConfigError{Key: path, Err: xerr.Wrapf(err, "invalid %s rule", rule)}
+		}
+		if (rule == "min" && actual < bound) || (rule == "max" && actual > bound) {
+			op := ">="
+			if rule == "max" {
+				op = "<="
+			}
+			return &ConfigError{Key: path, Err: xerr.Errorf("must be %s %s, got %v", op, limit, v.Interface())}
+		}
+	}
+
+	return nil
+}
+
+// measure returns the value compared by min/max rules and the limit parsed in the same unit
+func measure(v reflect.Value, limit string) (float64, float64, error) {
+	switch v.Type() {
+	case _durationType:
+		d, err := time.ParseDuration(limit)
+		return float64(v.Int()), float64(d), xerr.WithStack(err)
+	case _byteSizeType:
+		size, err := ParseByteSize(limit)
+		return float64(v.Int()), float64(size), err
+	}
+
+	bound, err := strconv.ParseFloat(limit, 64)
+	if err != nil {
+		return 0, 0, xerr.WithStack(err)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), bound, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), bound, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), bound, nil
+	case reflect.String, reflect.Slice, reflect.Map:
+		return float64(v.Len()), bound, nil
+	}
+	return 0, 0, xerr.Errorf("not supported for %s", v.Type())
+}
+
+// parseRules parses a validate tag such as "required,min=1,oneof=a b" into a rule map
+func parseRules(tag string) map[string]string {
+	r := make(map[string]string)
+	for _, rule := range strings.Split(tag, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name != "" {
+			r[name] = value
+		}
+	}
+	return r
+}
+
+// toDuration converts a string such as "30s" to a duration, a number or a string without unit
+// such as "1.5" is read as seconds
+func toDuration(v interface{}) (time.Duration, error) {
+	if s, ok := v.(string); ok {
+		s = strings.TrimSpace(s)
+		d, err := time.ParseDuration(s)
+		if err == nil {
+			return d, nil
+		}
+		if _, ferr := strconv.ParseFloat(s, 64); ferr != nil {
+			return 0, xerr.WithStack(err)
+		}
+	}
+
+	seconds, err := toFloat64(v)
+	if err != nil {
+		return 0, err
+	}
+	ns := seconds * float64(time.Second)
+	if math.IsNaN(ns) || ns < math.MinInt64 || ns >= math.MaxInt64 {
+		return 0, xerr.Errorf("%v seconds overflows a duration", seconds)
+	}
+	return time.Duration(ns), nil
+}
+
+// toByteSize converts a string such as "10MB" or a number of bytes to a ByteSize
+func toByteSize(v interface{}) (ByteSize, error) {
+	if s, ok := v.(string); ok {
+		return ParseByteSize(s)
+	}
+	r, err := toInt64(v)
+	return ByteSize(r), err
+}
+
+// allocate returns the value a pointer points to, allocating it first if it is nil
+func allocate(v reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Ptr {
+		return v
+	}
+	if v.IsNil() {
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+	return v.Elem()
+}
+
+// indirectType returns the type a pointer type points to
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isLeafType reports whether a struct type is bound from a single value rather than from a section
+func isLeafType(t reflect.Type) bool {
+	return t == _timeType || reflect.PointerTo(t).Implements(_unmarshalerType)
+}
+
+// contains reports whether s is in a
+func contains(a []string, s string) bool {
+	for _, e := range a {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package xconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testRedisConfig struct {
+	Addr     string        `config:"addr" validate:"required"`
+	DB       int           `config:"db" default:"0" validate:"min=0,max=15"`
+	Timeout  time.Duration `config:"timeout" default:"5s"`
+	PoolSize int           `config:"pool_size" default:"10"`
+}
+
+type testServerConfig struct {
+	Name     string            `config:"name" validate:"required"`
+	Mode     string            `config:"mode" default:"release" validate:"oneof=debug release"`
+	Port     int               `json:"port" validate:"min=1,max=65535"`
+	MaxBody  ByteSize          `config:"max_body" default:"1MB" validate:"max=10MB"`
+	Hosts    []string          `config:"hosts" default:"a,b"`
+	Labels   map[string]string `config:"labels"`
+	Redis    testRedisConfig   `config:"redis"`
+	Internal string            `config:"-"`
+}
+
+func TestMapConfiguration_Bind(t *testing.T) {
+	t.Run("Valid section with defaults", func(t *testing.T) {
+		config := MapConfiguration{
+			"server": map[string]interface{}{
+				"NAME":     "api",
+				"port":     float64(8080),
+				"max_body": "10MB",
+				"labels":   map[string]interface{}{"team": "core"},
+				"redis": map[string]interface{}{
+					"addr":    "localhost:6379",
+					"timeout": "30s",
+				},
+				"Internal": "ignored",
+			},
+		}
+
+		var server testServerConfig
+		if err := config.Bind("server", &server); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if server.Name != "api" || server.Port != 8080 || server.Mode != "release" {
+			t.Errorf("Unexpected values %+v", server)
+		}
+		if server.MaxBody != 10*MegaByte {
+			t.Errorf("Expected 10MB, got %v", server.MaxBody)
+		}
+		if len(server.Hosts) != 2 || server.Hosts[1] != "b" {
+			t.Errorf("Expected default hosts [a b], got %v", server.Hosts)
+		}
+		if server.Labels["team"] != "core" {
+			t.Errorf("Expected label team=core, got %v", server.Labels)
+		}
+		if server.Redis.Timeout != 30*time.Second || server.Redis.PoolSize != 10 {
+			t.Errorf("Unexpected redis config %+v", server.Redis)
+		}
+		if server.Internal != "" {
+			t.Errorf("Expected skipped field to stay empty, got '%s'", server.Internal)
+		}
+	})
+
+	t.Run("Every invalid field is reported", func(t *testing.T) {
+		config := MapConfiguration{
+			"server": map[string]interface{}{
+				"mode":     "test",
+				"port":     float64(70000),
+				"max_body": "1GB",
+				"redis": map[string]interface{}{
+					"db":      float64(20),
+					"timeout": "soon",
+				},
+			},
+		}
+
+		var server testServerConfig
+		err := config.Bind("server", &server)
+
+		var configErrs ConfigErrors
+		if !errors.As(err, &configErrs) {
+			t.Fatalf("Expected ConfigErrors, got %v", err)
+		}
+
+		keys := make([]string, 0, len(configErrs))
+		for _, e := range configErrs {
+			keys = append(keys, e.Key)
+		}
+		expected := []string{"server.name", "server.mode", "server.port", "server.max_body", "server.redis.addr", "server.redis.db", "server.redis.timeout"}
+		if strings.Join(keys, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected errors for %v, got %v", expected, keys)
+		}
+	})
+
+	t.Run("Times accept the layouts of GetTime", func(t *testing.T) {
+		config := MapConfiguration{
+			"rfc3339": "2024-05-01T10:00:00Z",
+			"day":     "2024-05-01",
+			"local":   "2024-05-01 10:00:00",
+			"unix":    float64(1714557600),
+			"invalid": "yesterday",
+		}
+		var times struct {
+			RFC3339 time.Time `config:"rfc3339"`
+			Day     time.Time `config:"day"`
+			Local   time.Time `config:"local"`
+			Unix    time.Time `config:"unix"`
+		}
+		if err := config.Bind("", &times); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !times.Unix.Equal(times.RFC3339) || !times.Local.Equal(times.RFC3339) || times.Day.Day() != 1 {
+			t.Errorf("Unexpected times %+v", times)
+		}
+
+		var invalid struct {
+			Invalid time.Time `config:"invalid"`
+		}
+		if err := config.Bind("", &invalid); err == nil {
+			t.Error("Expected error for an invalid time")
+		}
+	})
+
+	t.Run("Invalid target", func(t *testing.T) {
+		var server testServerConfig
+		if err := (&MapConfiguration{}).Bind("", server); err == nil {
+			t.Error("Expected error for non-pointer target")
+		}
+	})
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ByteSize
+		wantErr  bool
+	}{
+		{"512", 512, false},
+		{"64KB", 64 * KiloByte, false},
+		{"1.5 GiB", GigaByte + GigaByte/2, false},
+		{"10mb", 10 * MegaByte, false},
+		{"10XB", 0, true},
+		{"MB", 0, true},
+		{"8TB", 8 * TeraByte, false},
+		{"8388608TB", 0, true},
+		{"1e30", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseByteSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseByteSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseByteSize(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package xconfig
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DreamvatLab/go/xerr"
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// configSnapshot is an immutable view of a loaded configuration
+type configSnapshot struct {
+	MapConfiguration
+	RawJson []byte
+}
+
+// configSubscription is a callback registered through OnChange
+type configSubscription struct {
+	key      string
+	callback func(oldValue, newValue interface{})
+}
+
+// configStore holds the current configuration snapshot of a reloadable provider.
+// Readers always see a complete snapshot, a reload replaces it atomically and then notifies the subscribers.
+type configStore struct {
+	current       atomic.Pointer[configSnapshot]
+	mu            sync.Mutex
+	subscriptions []*configSubscription
+	pending       []configChange // notifications queued by swap, in the order of the swaps
+	delivering    bool
+}
+
+// snapshot returns the current configuration snapshot
+func (x *configStore) snapshot() *configSnapshot {
+	r := x.current.Load()
+	if r == nil {
+		return &configSnapshot{MapConfiguration: make(MapConfiguration), RawJson: []byte("{}")}
+	}
+	return r
+}
+
+// configChange is a notification queued by swap
+type configChange struct {
+	subscription       *configSubscription
+	oldValue, newValue interface{}
+}
+
+// swap replaces the current configuration and queues the notifications of the subscribers whose key changed,
+// deliver sends them
+func (x *configStore) swap(m MapConfiguration) error {
+	rawJson, err := json.Marshal(m)
+	if err != nil {
+		return xerr.WithStack(err)
+	}
+
+	x.mu.Lock()
+	old := x.current.Swap(&configSnapshot{MapConfiguration: m, RawJson: rawJson})
+	if old != nil {
+		for _, s := range x.subscriptions {
+			var oldValue, newValue interface{}
+			if s.key == "" {
+				oldValue, newValue = old.MapConfiguration, m
+			} else {
+				oldValue, newValue = getValue(s.key, old.MapConfiguration), getValue(s.key, m)
+			}
+
+			if !reflect.DeepEqual(oldValue, newValue) {
+				x.pending = append(x.pending, configChange{subscription: s, oldValue: oldValue, newValue: newValue})
+			}
+		}
+	}
+	x.mu.Unlock()
+	return nil
+}
+
+// deliver invokes the callbacks of the queued notifications in order.
+// The callbacks run without any lock held, so they can call OnChange or reload the provider. When another
+// goroutine is already delivering, deliver returns at once and that goroutine sends the queued notifications,
+// so a callback never runs concurrently with another one nor sees the changes out of order.
+func (x *configStore) deliver() {
+	x.mu.Lock()
+	if x.delivering {
+		x.mu.Unlock()
+		return
+	}
+	x.delivering = true
+	for len(x.pending) > 0 {
+		changes := x.pending
+		x.pending = nil
+		x.mu.Unlock()
+
+		for _, c := range changes {
+			notify(c.subscription, c.oldValue, c.newValue)
+		}
+		x.mu.Lock()
+	}
+	x.delivering = false
+	x.mu.Unlock()
+}
+
+// notify invokes a subscription callback, a panicking callback must not break the reload loop
+func notify(s *configSubscription, oldValue, newValue interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			xlog.Errorf("config change callback for key '%s' panicked: %v", s.key, r)
+		}
+	}()
+	s.callback(oldValue, newValue)
+}
+
+// OnChange registers a callback invoked after a reload changed the value under key.
+// An empty key subscribes to any change, the callback then receives the old and new MapConfiguration.
+func (x *configStore) OnChange(key string, callback func(oldValue, newValue interface{})) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.subscriptions = append(x.subscriptions, &configSubscription{key: key, callback: callback})
+}
+
+func (x *configStore) GetStruct(key string, target interface{}) error {
+	return x.snapshot().GetStruct(key, target)
+}
+
+func (x *configStore) Bind(key string, target interface{}) error {
+	return x.snapshot().Bind(key, target)
+}
+
+func (x *configStore) GetString(key string) string {
+	return x.snapshot().GetString(key)
+}
+
+func (x *configStore) GetStringDefault(key string, defaultValue string) string {
+	return x.snapshot().GetStringDefault(key, defaultValue)
+}
+
+func (x *configStore) GetBool(key string) bool {
+	return x.snapshot().GetBool(key)
+}
+
+func (x *configStore) GetFloat64(key string) float64 {
+	return x.snapshot().GetFloat64(key)
+}
+
+func (x *configStore) GetInt(key string) int {
+	return x.snapshot().GetInt(key)
+}
+
+func (x *configStore) GetIntDefault(key string, defaultValue int) int {
+	return x.snapshot().GetIntDefault(key, defaultValue)
+}
+
+func (x *configStore) GetStringSlice(key string) []string {
+	return x.snapshot().GetStringSlice(key)
+}
+
+func (x *configStore) GetIntSlice(key string) []int {
+	return x.snapshot().GetIntSlice(key)
+}
+
+func (x *configStore) GetInt64(key string) int64 {
+	return x.snapshot().GetInt64(key)
+}
+
+func (x *configStore) GetInt64Default(key string, defaultValue int64) int64 {
+	return x.snapshot().GetInt64Default(key, defaultValue)
+}
+
+func (x *configStore) GetDuration(key string) time.Duration {
+	return x.snapshot().GetDuration(key)
+}
+
+func (x *configStore) GetDurationDefault(key string, defaultValue time.Duration) time.Duration {
+	return x.snapshot().GetDurationDefault(key, defaultValue)
+}
+
+func (x *configStore) GetTime(key string) time.Time {
+	return x.snapshot().GetTime(key)
+}
+
+func (x *configStore) GetTimeDefault(key string, defaultValue time.Time) time.Time {
+	return x.snapshot().GetTimeDefault(key, defaultValue)
+}
+
+func (x *configStore) GetByteSize(key string) ByteSize {
+	return x.snapshot().GetByteSize(key)
+}
+
+func (x *configStore) GetByteSizeDefault(key string, defaultValue ByteSize) ByteSize {
+	return x.snapshot().GetByteSizeDefault(key, defaultValue)
+}
+
+func (x *configStore) GetFloat64Slice(key string) []float64 {
+	return x.snapshot().GetFloat64Slice(key)
+}
+
+func (x *configStore) GetFloat64SliceDefault(key string, defaultValue []float64) []float64 {
+	return x.snapshot().GetFloat64SliceDefault(key, defaultValue)
+}
+
+func (x *configStore) GetStringMap(key string) map[string]interface{} {
+	return x.snapshot().GetStringMap(key)
+}
+
+func (x *configStore) GetStringMapDefault(key string, defaultValue map[string]interface{}) map[string]interface{} {
+	return x.snapshot().GetStringMapDefault(key, defaultValue)
+}
+
+func (x *configStore) GetStringMapString(key string) map[string]string {
+	return x.snapshot().GetStringMapString(key)
+}
+
+func (x *configStore) GetStringMapStringDefault(key string, defaultValue map[string]string) map[string]string {
+	return x.snapshot().GetStringMapStringDefault(key, defaultValue)
+}
+
+func (x *configStore) GetMapSlice(key string) []MapConfiguration {
+	return x.snapshot().GetMapSlice(key)
+}
+
+func (x *configStore) GetMapSliceDefault(key string, defaultValue []MapConfiguration) []MapConfiguration {
+	return x.snapshot().GetMapSliceDefault(key, defaultValue)
+}
+
+func (x *configStore) Has(key string) bool {
+	return x.snapshot().Has(key)
+}
+
+func (x *configStore) Query(key string) ([]interface{}, error) {
+	return x.snapshot().Query(key)
+}
+
+func (x *configStore) Keys(key string) []string {
+	return x.snapshot().Keys(key)
+}
+
+func (x *configStore) LookupString(key string) (string, bool, error) {
+	return x.snapshot().LookupString(key)
+}
+
+func (x *configStore) LookupBool(key string) (bool, bool, error) {
+	return x.snapshot().LookupBool(key)
+}
+
+func (x *configStore) LookupFloat64(key string) (float64, bool, error) {
+	return x.snapshot().LookupFloat64(key)
+}
+
+func (x *configStore) LookupInt(key string) (int, bool, error) {
+	return x.snapshot().LookupInt(key)
+}
+
+func (x *configStore) LookupStringSlice(key string) ([]string, bool, error) {
+	return x.snapshot().LookupStringSlice(key)
+}
+
+func (x *configStore) LookupIntSlice(key string) ([]int, bool, error) {
+	return x.snapshot().LookupIntSlice(key)
+}
+
+func (x *configStore) LookupInt64(key string) (int64, bool, error) {
+	return x.snapshot().LookupInt64(key)
+}
+
+func (x *configStore) LookupDuration(key string) (time.Duration, bool, error) {
+	return x.snapshot().LookupDuration(key)
+}
+
+func (x *configStore) LookupTime(key string) (time.Time, bool, error) {
+	return x.snapshot().LookupTime(key)
+}
+
+func (x *configStore) LookupByteSize(key string) (ByteSize, bool, error) {
+	return x.snapshot().LookupByteSize(key)
+}
+
+func (x *configStore) LookupFloat64Slice(key string) ([]float64, bool, error) {
+	return x.snapshot().LookupFloat64Slice(key)
+}
+
+func (x *configStore) LookupStringMap(key string) (map[string]interface{}, bool, error) {
+	return x.snapshot().LookupStringMap(key)
+}
+
+func (x *configStore) LookupStringMapString(key string) (map[string]string, bool, error) {
+	return x.snapshot().LookupStringMapString(key)
+}
+
+func (x *configStore) LookupMapSlice(key string) ([]MapConfiguration, bool, error) {
+	return x.snapshot().LookupMapSlice(key)
+}
+
+func (x *configStore) MustString(key string) string {
+	return x.snapshot().MustString(key)
+}
+
+func (x *configStore) MustBool(key string) bool {
+	return x.snapshot().MustBool(key)
+}
+
+func (x *configStore) MustFloat64(key string) float64 {
+	return x.snapshot().MustFloat64(key)
+}
+
+func (x *configStore) MustInt(key string) int {
+	return x.snapshot().MustInt(key)
+}
+
+func (x *configStore) MustStringSlice(key string) []string {
+	return x.snapshot().MustStringSlice(key)
+}
+
+func (x *configStore) MustIntSlice(key string) []int {
+	return x.snapshot().MustIntSlice(key)
+}
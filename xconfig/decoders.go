@@ -0,0 +1,177 @@
+package xconfig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/DreamvatLab/go/xerr"
+	"gopkg.in/yaml.v3"
+)
+
+// _decoders maps a lower-case file extension to the function decoding files of that format.
+// Every decoder returns a tree made of the same types encoding/json produces, so the getters
+// behave identically regardless of the source format.
+var _decoders = map[string]func(data []byte) (map[string]interface{}, error){
+	".json": decodeJson,
+	".yaml": decodeYaml,
+	".yml":  decodeYaml,
+	".toml": decodeToml,
+	".env":  decodeDotenv,
+}
+
+// decodeFile decodes data using the decoder registered for the extension of path
+func decodeFile(path string, data []byte) (map[string]interface{}, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	decoder, ok := _decoders[ext]
+	if !ok {
+		return nil, xerr.Errorf("unsupported config file format: %s", path)
+	}
+	return decoder(data)
+}
+
+// decodeJson decodes a JSON document into a configuration tree
+func decodeJson(data []byte) (map[string]interface{}, error) {
+	r := make(map[string]interface{})
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return nil, xerr.WithStack(err)
+	}
+	return r, nil
+}
+
+// decodeYaml decodes a YAML document into a configuration tree
+func decodeYaml(data []byte) (map[string]interface{}, error) {
+	var v interface{}
+	err := yaml.Unmarshal(data, &v)
+	if err != nil {
+		return nil, xerr.WithStack(err)
+	}
+	if v == nil {
+		return make(map[string]interface{}), nil
+	}
+	return normalizeTree(v)
+}
+
+// decodeToml decodes a TOML document into a configuration tree
+func decodeToml(data []byte) (map[string]interface{}, error) {
+	v := make(map[string]interface{})
+	err := toml.Unmarshal(data, &v)
+	if err != nil {
+		return nil, xerr.WithStack(err)
+	}
+	return normalizeTree(v)
+}
+
+// decodeDotenv decodes a dotenv file into a configuration tree.
+//
+// Each "KEY=VALUE" line (optionally prefixed with "export") sets a key. Names are lower-cased and
+// split on "__" and "." to build nested keys, so REDIS__ADDR sets "redis.addr". Double-quoted values
+// support the usual escapes, single-quoted values are literal and both are always strings. Unquoted
+// values that are valid JSON numbers, booleans, arrays or objects are decoded as such.
+func decodeDotenv(data []byte) (map[string]interface{}, error) {
+	r := make(map[string]interface{})
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, rawValue, ok := strings.Cut(line, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			return nil, xerr.Errorf("invalid dotenv line %d: %s", lineNumber, line)
+		}
+
+		value, err := parseDotenvValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, xerr.Wrapf(err, "invalid dotenv line %d", lineNumber)
+		}
+
+		keys := strings.FieldsFunc(strings.ReplaceAll(name, "__", "."), func(c rune) bool { return c == '.' })
+		setPath(r, keys, value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, xerr.WithStack(err)
+	}
+
+	return r, nil
+}
+
+// parseDotenvValue parses the value part of a dotenv line
+func parseDotenvValue(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		end := strings.LastIndex(s, `"`)
+		if end == 0 {
+			return nil, xerr.New("unterminated double-quoted value")
+		}
+		return strconv.Unquote(s[:end+1])
+	case strings.HasPrefix(s, "'"):
+		end := strings.LastIndex(s, "'")
+		if end == 0 {
+			return nil, xerr.New("unterminated single-quoted value")
+		}
+		return s[1:end], nil
+	}
+
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err == nil && v != nil {
+		if _, isString := v.(string); !isString {
+			return v, nil
+		}
+	}
+	return s, nil
+}
+
+// normalizeTree converts a decoded document into the types encoding/json produces
+func normalizeTree(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(stringifyKeys(v))
+	if err != nil {
+		return nil, xerr.WithStack(err)
+	}
+
+	r, err := decodeJson(data)
+	if err != nil {
+		return nil, xerr.WithMessage(err, "config root must be a map")
+	}
+	return r, nil
+}
+
+// stringifyKeys converts maps with non-string keys, which encoding/json cannot marshal, into string keyed maps
+func stringifyKeys(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		r := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			r[fmt.Sprint(k)] = stringifyKeys(e)
+		}
+		return r
+	case map[string]interface{}:
+		for k, e := range val {
+			val[k] = stringifyKeys(e)
+		}
+		return val
+	case []interface{}:
+		for i, e := range val {
+			val[i] = stringifyKeys(e)
+		}
+		return val
+	}
+	return v
+}
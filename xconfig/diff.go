@@ -0,0 +1,57 @@
+package xconfig
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ConfigChange is a value that differs between two configurations
+type ConfigChange struct {
+	// Key is the full path of the value
+	Key string
+	// Old is the value in the first configuration, nil if the key is missing there
+	Old interface{}
+	// New is the value in the second configuration, nil if the key is missing there
+	New interface{}
+}
+
+// Diff compares two configurations key by key, e.g. the resolved configurations of two environments.
+// Sections are compared entry by entry while any other value, arrays included, is compared as a whole.
+// The changes are sorted by key.
+func Diff(a, b MapConfiguration) []ConfigChange {
+	r := make([]ConfigChange, 0)
+	diffMaps("", a, b, &r)
+	return r
+}
+
+// diffMaps appends the differences between the sections a and b to r
+func diffMaps(path string, a, b map[string]interface{}, r *[]ConfigChange) {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := joinPath(path, key)
+		oldValue, newValue := a[key], b[key]
+
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		switch {
+		case oldIsMap && newIsMap:
+			diffMaps(keyPath, oldMap, newMap, r)
+		case oldIsMap && newValue == nil:
+			diffMaps(keyPath, oldMap, nil, r)
+		case newIsMap && oldValue == nil:
+			diffMaps(keyPath, nil, newMap, r)
+		case !reflect.DeepEqual(oldValue, newValue):
+			*r = append(*r, ConfigChange{Key: keyPath, Old: oldValue, New: newValue})
+		}
+	}
+}
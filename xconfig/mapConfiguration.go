@@ -1,64 +1,57 @@
 package xconfig
 
 import (
-	"fmt"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/DreamvatLab/go/xconv"
+	"github.com/DreamvatLab/go/xerr"
+	"github.com/DreamvatLab/go/xlog"
 )
 
 // MapConfiguration represents a configuration stored in a map structure
 type MapConfiguration map[string]interface{}
 
-// GetMap retrieves a nested map configuration by key
-func (x *MapConfiguration) GetMap(key string) (r MapConfiguration) {
-	v := getValue(key, *x)
-	if v != nil {
-		a, ok := v.(interface{})
-		if !ok {
-			fmt.Printf("convert failed. %t -> interface{}", v)
-		} else {
-			b := a.(map[string]interface{})
-			r = MapConfiguration(b)
-
-			return r
-		}
+// GetStruct retrieves a configuration section as a struct, using the json tags of the target
+func (x *MapConfiguration) GetStruct(key string, target interface{}) error {
+	v, err := lookupValue(key, *x)
+	if err != nil {
+		return err
 	}
-	return nil
+	if v == nil {
+		return &ConfigError{Key: key, Err: ErrKeyNotFound}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return xerr.WithStack(err)
+	}
+	return xerr.WithStack(json.Unmarshal(data, target))
+}
+
+// GetMap retrieves a nested map configuration by key
+func (x *MapConfiguration) GetMap(key string) MapConfiguration {
+	r, _, err := x.LookupMap(key)
+	warnIfErr(err)
+	return r
 }
 
 // GetMapSlice retrieves a slice of map configurations by key
 func (x *MapConfiguration) GetMapSlice(key string) []MapConfiguration {
-	v := getValue(key, *x)
-	if v != nil {
-		a, ok := v.([]interface{})
-		if !ok {
-			fmt.Printf("convert failed. %t -> []interface{}", v)
-			return nil
-		}
-		r := make([]MapConfiguration, 0, len(a))
-		for _, b := range a {
-			if m, ok := b.(map[string]interface{}); ok {
-				r = append(r, MapConfiguration(m))
-			}
-		}
-		return r
-	}
-	return nil
+	r, _, err := x.LookupMapSlice(key)
+	warnIfErr(err)
+	return r
 }
 
 // GetString retrieves a string value by key
 func (x *MapConfiguration) GetString(key string) string {
-	v := getValue(key, *x)
-	if v != nil {
-		r, ok := v.(string)
-		if !ok {
-			fmt.Printf("convert failed. %t -> string", v)
-		} else {
-			return r
-		}
-	}
-	return ""
+	r, _, err := x.LookupString(key)
+	warnIfErr(err)
+	return r
 }
 
 // GetStringDefault retrieves a string value by key with a default value
@@ -72,39 +65,22 @@ func (x *MapConfiguration) GetStringDefault(key, defaultValue string) string {
 
 // GetBool retrieves a boolean value by key
 func (x *MapConfiguration) GetBool(key string) bool {
-	v := getValue(key, *x)
-	if v != nil {
-		r, ok := v.(bool)
-		if !ok {
-			fmt.Printf("convert failed. %t -> bool", v)
-		} else {
-			return r
-		}
-	}
-	return false
+	r, _, err := x.LookupBool(key)
+	warnIfErr(err)
+	return r
 }
 
 // GetFloat64 retrieves a float64 value by key
 func (x *MapConfiguration) GetFloat64(key string) float64 {
-	v := getValue(key, *x)
-	if v != nil {
-		r, ok := v.(float64)
-		if !ok {
-			fmt.Printf("convert failed. %t -> float64", v)
-		} else {
-			return r
-		}
-	}
-	return 0
+	r, _, err := x.LookupFloat64(key)
+	warnIfErr(err)
+	return r
 }
 
-// GetInt retrieves an integer value by key
+// GetInt retrieves an integer value by key, a number with a fraction is truncated
+// and a value which is not a number is read as 0
 func (x *MapConfiguration) GetInt(key string) int {
-	v := getValue(key, *x)
-	if v != nil {
-		return xconv.ToInt(v)
-	}
-	return 0
+	return int(x.GetInt64(key))
 }
 
 // GetIntDefault retrieves an integer value by key with a default value
@@ -116,81 +92,567 @@ func (x *MapConfiguration) GetIntDefault(key string, defaultValue int) int {
 	return defaultValue
 }
 
-// GetStringSlice retrieves a slice of strings by key
+// GetStringSlice retrieves a slice of strings by key, skipping the elements which are not strings
 func (x *MapConfiguration) GetStringSlice(key string) []string {
-	v := getValue(key, *x)
-	if v != nil {
-		slice, ok := v.([]interface{})
-		if !ok {
-			fmt.Printf("convert failed. %t -> interface slice", v)
-		} else {
-			var r []string
-			for _, e := range slice {
-				a, ok := e.(string)
-				if ok {
-					r = append(r, a)
-				}
-			}
-			return r
+	return getSlice(*x, key, toString)
+}
+
+// GetIntSlice retrieves a slice of integers by key, truncating the numbers with a fraction
+// and skipping the elements which are not numbers
+func (x *MapConfiguration) GetIntSlice(key string) []int {
+	return getSlice(*x, key, truncateInt)
+}
+
+// GetInt64 retrieves a 64-bit integer value by key, a number with a fraction is truncated
+// and a value which is not a number is read as 0
+func (x *MapConfiguration) GetInt64(key string) int64 {
+	r, _, err := lookup(*x, key, "int64", truncateInt64)
+	warnIfErr(err)
+	return r
+}
+
+// GetInt64Default retrieves a 64-bit integer value by key, returning defaultValue if it is missing or invalid
+func (x *MapConfiguration) GetInt64Default(key string, defaultValue int64) int64 {
+	return orDefault(defaultValue)(x.LookupInt64(key))
+}
+
+// GetDuration retrieves a duration such as "30s" by key, a number is read as seconds
+func (x *MapConfiguration) GetDuration(key string) time.Duration {
+	r, _, err := x.LookupDuration(key)
+	warnIfErr(err)
+	return r
+}
+
+// GetDurationDefault retrieves a duration by key, returning defaultValue if it is missing or invalid
+func (x *MapConfiguration) GetDurationDefault(key string, defaultValue time.Duration) time.Duration {
+	return orDefault(defaultValue)(x.LookupDuration(key))
+}
+
+// GetTime retrieves a time by key, see LookupTime for the accepted formats
+func (x *MapConfiguration) GetTime(key string) time.Time {
+	r, _, err := x.LookupTime(key)
+	warnIfErr(err)
+	return r
+}
+
+// GetTimeDefault retrieves a time by key, returning defaultValue if it is missing or invalid
+func (x *MapConfiguration) GetTimeDefault(key string, defaultValue time.Time) time.Time {
+	return orDefault(defaultValue)(x.LookupTime(key))
+}
+
+// GetByteSize retrieves a size such as "10MB" by key, a number is read as bytes
+func (x *MapConfiguration) GetByteSize(key string) ByteSize {
+	r, _, err := x.LookupByteSize(key)
+	warnIfErr(err)
+	return r
+}
+
+// GetByteSizeDefault retrieves a size by key, returning defaultValue if it is missing or invalid
+func (x *MapConfiguration) GetByteSizeDefault(key string, defaultValue ByteSize) ByteSize {
+	return orDefault(defaultValue)(x.LookupByteSize(key))
+}
+
+// GetFloat64Slice retrieves a slice of float64 by key, skipping the elements which are not numbers
+func (x *MapConfiguration) GetFloat64Slice(key string) []float64 {
+	return getSlice(*x, key, toFloat64)
+}
+
+// GetFloat64SliceDefault retrieves a slice of float64 by key, returning defaultValue if it is missing or invalid
+func (x *MapConfiguration) GetFloat64SliceDefault(key string, defaultValue []float64) []float64 {
+	return orDefault(defaultValue)(x.LookupFloat64Slice(key))
+}
+
+// GetStringMap retrieves a configuration section by key as a map
+func (x *MapConfiguration) GetStringMap(key string) map[string]interface{} {
+	r, _, err := x.LookupStringMap(key)
+	warnIfErr(err)
+	return r
+}
+
+// GetStringMapDefault retrieves a configuration section by key as a map, returning defaultValue if it is missing or invalid
+func (x *MapConfiguration) GetStringMapDefault(key string, defaultValue map[string]interface{}) map[string]interface{} {
+	return orDefault(defaultValue)(x.LookupStringMap(key))
+}
+
+// GetStringMapString retrieves a configuration section of scalar values by key as a map of strings
+func (x *MapConfiguration) GetStringMapString(key string) map[string]string {
+	r, _, err := x.LookupStringMapString(key)
+	warnIfErr(err)
+	return r
+}
+
+// GetStringMapStringDefault retrieves a configuration section by key as a map of strings,
+// returning defaultValue if it is missing or invalid
+func (x *MapConfiguration) GetStringMapStringDefault(key string, defaultValue map[string]string) map[string]string {
+	return orDefault(defaultValue)(x.LookupStringMapString(key))
+}
+
+// GetMapSliceDefault retrieves a slice of map configurations by key, returning defaultValue if it is missing or invalid
+func (x *MapConfiguration) GetMapSliceDefault(key string, defaultValue []MapConfiguration) []MapConfiguration {
+	return orDefault(defaultValue)(x.LookupMapSlice(key))
+}
+
+// Has reports whether the configuration holds a non-null value under key
+func (x *MapConfiguration) Has(key string) bool {
+	if key == "" {
+		return true
+	}
+	return getValue(key, *x) != nil
+}
+
+// Keys returns the sorted keys of the section under key, an empty key returns the top-level keys.
+// It returns nil if there is no section under key.
+func (x *MapConfiguration) Keys(key string) []string {
+	var v interface{} = *x
+	if key != "" {
+		v = getValue(key, *x)
+	}
+
+	m, err := toMap(v)
+	if err != nil {
+		return nil
+	}
+
+	r := make([]string, 0, len(m))
+	for k := range m {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// Query retrieves every value matching a key path holding * wildcards, e.g. "servers.*.host",
+// with their secret placeholders expanded. Section entries are visited in key order and array elements in index order.
+func (x *MapConfiguration) Query(key string) ([]interface{}, error) {
+	segments, err := parsePath(key)
+	if err != nil {
+		return nil, &ConfigError{Key: key, Err: err}
+	}
+
+	r := make([]interface{}, 0)
+	queryPath("", segments, *x, func(path string, v interface{}) {
+		if err != nil || v == nil {
+			return
 		}
+		v, err = expandSecrets(path, v)
+		r = append(r, v)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return make([]string, 0)
+	return r, nil
 }
 
-// GetIntSlice retrieves a slice of integers by key
-func (x *MapConfiguration) GetIntSlice(key string) []int {
-	v := getValue(key, *x)
-	if v != nil {
-		slice, ok := v.([]interface{})
-		if !ok {
-			fmt.Printf("convert failed. %t -> interface slice", v)
-		} else {
-			var r []int
-			for _, e := range slice {
-				switch val := e.(type) {
-				case float64:
-					r = append(r, int(val))
-				case int:
-					r = append(r, val)
-				case int64:
-					r = append(r, int(val))
-				case int32:
-					r = append(r, int(val))
-				}
-			}
-			return r
+// LookupMap retrieves a nested map configuration by key, reporting whether the key exists
+// and a *ConfigError if the value is not a map
+func (x *MapConfiguration) LookupMap(key string) (MapConfiguration, bool, error) {
+	return lookup(*x, key, "object", toMap)
+}
+
+// LookupMapSlice retrieves a slice of map configurations by key, reporting whether the key exists
+// and a *ConfigError if the value is not an array of maps
+func (x *MapConfiguration) LookupMapSlice(key string) ([]MapConfiguration, bool, error) {
+	return lookupSlice(*x, key, "object", toMap)
+}
+
+// LookupString retrieves a string value by key, reporting whether the key exists
+// and a *ConfigError if the value is not a string
+func (x *MapConfiguration) LookupString(key string) (string, bool, error) {
+	return lookup(*x, key, "string", toString)
+}
+
+// LookupBool retrieves a boolean value by key, reporting whether the key exists
+// and a *ConfigError if the value is neither a boolean nor a string holding one
+func (x *MapConfiguration) LookupBool(key string) (bool, bool, error) {
+	return lookup(*x, key, "bool", toBool)
+}
+
+// LookupFloat64 retrieves a float64 value by key, reporting whether the key exists
+// and a *ConfigError if the value is neither a number nor a string holding one
+func (x *MapConfiguration) LookupFloat64(key string) (float64, bool, error) {
+	return lookup(*x, key, "float64", toFloat64)
+}
+
+// LookupInt retrieves an integer value by key, reporting whether the key exists
+// and a *ConfigError if the value is neither an integral number nor a string holding one
+func (x *MapConfiguration) LookupInt(key string) (int, bool, error) {
+	return lookup(*x, key, "int", toInt)
+}
+
+// LookupStringSlice retrieves a slice of strings by key, reporting whether the key exists
+// and a *ConfigError naming the offending element if the value is not an array of strings
+func (x *MapConfiguration) LookupStringSlice(key string) ([]string, bool, error) {
+	return lookupSlice(*x, key, "string", toString)
+}
+
+// LookupIntSlice retrieves a slice of integers by key, reporting whether the key exists
+// and a *ConfigError naming the offending element if the value is not an array of integers
+func (x *MapConfiguration) LookupIntSlice(key string) ([]int, bool, error) {
+	return lookupSlice(*x, key, "int", toInt)
+}
+
+// LookupInt64 retrieves a 64-bit integer value by key, reporting whether the key exists
+// and a *ConfigError if the value is neither an integral number nor a string holding one
+func (x *MapConfiguration) LookupInt64(key string) (int64, bool, error) {
+	return lookup(*x, key, "int64", toInt64)
+}
+
+// LookupDuration retrieves a duration by key, reporting whether the key exists
+// and a *ConfigError if the value is neither a duration string nor a number of seconds
+func (x *MapConfiguration) LookupDuration(key string) (time.Duration, bool, error) {
+	return lookup(*x, key, "duration", toDuration)
+}
+
+// LookupTime retrieves a time by key, reporting whether the key exists and a *ConfigError if the value is invalid.
+// Strings are parsed as RFC 3339, "2006-01-02 15:04:05" or "2006-01-02", numbers as Unix seconds.
+func (x *MapConfiguration) LookupTime(key string) (time.Time, bool, error) {
+	return lookup(*x, key, "time", toTime)
+}
+
+// LookupByteSize retrieves a size by key, reporting whether the key exists
+// and a *ConfigError if the value is neither a size string nor a number of bytes
+func (x *MapConfiguration) LookupByteSize(key string) (ByteSize, bool, error) {
+	return lookup(*x, key, "size", toByteSize)
+}
+
+// LookupFloat64Slice retrieves a slice of float64 by key, reporting whether the key exists
+// and a *ConfigError naming the offending element if the value is not an array of numbers
+func (x *MapConfiguration) LookupFloat64Slice(key string) ([]float64, bool, error) {
+	return lookupSlice(*x, key, "float64", toFloat64)
+}
+
+// LookupStringMap retrieves a configuration section by key as a map, reporting whether the key exists
+// and a *ConfigError if the value is not a map
+func (x *MapConfiguration) LookupStringMap(key string) (map[string]interface{}, bool, error) {
+	r, found, err := x.LookupMap(key)
+	return map[string]interface{}(r), found, err
+}
+
+// LookupStringMapString retrieves a configuration section of scalar values by key as a map of strings,
+// reporting whether the key exists and a *ConfigError naming the offending entry if a value is not a scalar
+func (x *MapConfiguration) LookupStringMapString(key string) (map[string]string, bool, error) {
+	m, found, err := x.LookupMap(key)
+	if m == nil || err != nil {
+		return nil, found, err
+	}
+
+	r := make(map[string]string, len(m))
+	for k, v := range m {
+		s, err := toScalarString(v)
+		if err != nil {
+			return nil, true, newConvertError(joinPath(key, k), "string", v, err)
 		}
+		r[k] = s
 	}
-	return make([]int, 0)
+	return r, true, nil
 }
 
-// getValue retrieves a value from the configuration using a dot-separated key path
-func getValue(key string, c MapConfiguration) interface{} {
-	keys := strings.Split(key, ".")
-	keyCount := len(keys)
-
-	for i := 0; i < keyCount; i++ {
-		if i < keyCount-1 {
-			c = getNode(keys[i], c)
-			if c == nil {
-				break
-			}
-		} else {
-			r, ok := c[keys[i]]
-			if ok {
-				return r
+// MustString retrieves a string value by key and panics with a *ConfigError if it is missing or invalid
+func (x *MapConfiguration) MustString(key string) string {
+	return must[string](key, "string")(x.LookupString(key))
+}
+
+// MustBool retrieves a boolean value by key and panics with a *ConfigError if it is missing or invalid
+func (x *MapConfiguration) MustBool(key string) bool {
+	return must[bool](key, "bool")(x.LookupBool(key))
+}
+
+// MustFloat64 retrieves a float64 value by key and panics with a *ConfigError if it is missing or invalid
+func (x *MapConfiguration) MustFloat64(key string) float64 {
+	return must[float64](key, "float64")(x.LookupFloat64(key))
+}
+
+// MustInt retrieves an integer value by key and panics with a *ConfigError if it is missing or invalid
+func (x *MapConfiguration) MustInt(key string) int {
+	return must[int](key, "int")(x.LookupInt(key))
+}
+
+// MustStringSlice retrieves a slice of strings by key and panics with a *ConfigError if it is missing or invalid
+func (x *MapConfiguration) MustStringSlice(key string) []string {
+	return must[[]string](key, "[]string")(x.LookupStringSlice(key))
+}
+
+// MustIntSlice retrieves a slice of integers by key and panics with a *ConfigError if it is missing or invalid
+func (x *MapConfiguration) MustIntSlice(key string) []int {
+	return must[[]int](key, "[]int")(x.LookupIntSlice(key))
+}
+
+// lookup retrieves the value under key and converts it with convert
+func lookup[T any](c MapConfiguration, key, expected string, convert func(interface{}) (T, error)) (T, bool, error) {
+	var zero T
+
+	v, err := lookupValue(key, c)
+	if v == nil || err != nil {
+		return zero, v != nil, err
+	}
+
+	r, err := convert(v)
+	if err != nil {
+		return zero, true, newConvertError(key, expected, v, err)
+	}
+	return r, true, nil
+}
+
+// lookupSlice retrieves the array under key and converts each element with convert
+func lookupSlice[T any](c MapConfiguration, key, expected string, convert func(interface{}) (T, error)) ([]T, bool, error) {
+	v, err := lookupValue(key, c)
+	if v == nil || err != nil {
+		return nil, v != nil, err
+	}
+
+	slice, ok := v.([]interface{})
+	if !ok {
+		return nil, true, newTypeError(key, "array", v)
+	}
+
+	r := make([]T, 0, len(slice))
+	for i, e := range slice {
+		a, err := convert(e)
+		if err != nil {
+			return nil, true, newConvertError(joinPath(key, strconv.Itoa(i)), expected, e, err)
+		}
+		r = append(r, a)
+	}
+	return r, true, nil
+}
+
+// getSlice retrieves the array under key for the lenient getters, skipping the elements convert rejects.
+// It returns an empty slice if the key is missing or does not hold an array.
+func getSlice[T any](c MapConfiguration, key string, convert func(interface{}) (T, error)) []T {
+	v, err := lookupValue(key, c)
+	warnIfErr(err)
+	if v == nil {
+		return make([]T, 0)
+	}
+
+	slice, ok := v.([]interface{})
+	if !ok {
+		warnIfErr(newTypeError(key, "array", v))
+		return make([]T, 0)
+	}
+
+	r := make([]T, 0, len(slice))
+	for _, e := range slice {
+		if a, err := convert(e); err == nil {
+			r = append(r, a)
+		}
+	}
+	return r
+}
+
+// must returns a function turning the result of a lookup into a value, panicking on a missing key or an error
+func must[T any](key, expected string) func(T, bool, error) T {
+	return func(v T, found bool, err error) T {
+		if err != nil {
+			panic(err)
+		}
+		if !found {
+			panic(&ConfigError{Key: key, Expected: expected, Err: ErrKeyNotFound})
+		}
+		return v
+	}
+}
+
+// orDefault returns a function turning the result of a lookup into a value, falling back to defaultValue
+// on a missing key or an error
+func orDefault[T any](defaultValue T) func(T, bool, error) T {
+	return func(v T, found bool, err error) T {
+		warnIfErr(err)
+		if !found || err != nil {
+			return defaultValue
+		}
+		return v
+	}
+}
+
+// warnIfErr logs the conversion errors the lenient getters swallow
+func warnIfErr(err error) {
+	if err != nil {
+		xlog.Warn(err)
+	}
+}
+
+// errWrongType is returned by the converters for a value of an unexpected type
+var errWrongType = xerr.New("wrong type")
+
+// newConvertError creates a ConfigError for a value a converter rejected
+func newConvertError(key, expected string, v interface{}, err error) *ConfigError {
+	r := newTypeError(key, expected, v)
+	if err != errWrongType {
+		r.Err = err
+	}
+	return r
+}
+
+func toMap(v interface{}) (MapConfiguration, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return MapConfiguration(val), nil
+	case MapConfiguration:
+		return val, nil
+	}
+	return nil, errWrongType
+}
+
+func toString(v interface{}) (string, error) {
+	r, ok := v.(string)
+	if !ok {
+		return "", errWrongType
+	}
+	return r, nil
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch val := v.(type) {
+	case bool:
+		return val, nil
+	case string:
+		r, err := strconv.ParseBool(val)
+		return r, xerr.WithStack(err)
+	}
+	return false, errWrongType
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	switch val := v.(type) {
+	case float64:
+		return val, nil
+	case float32:
+		return float64(val), nil
+	case int:
+		return float64(val), nil
+	case int64:
+		return float64(val), nil
+	case int32:
+		return float64(val), nil
+	case string:
+		r, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return r, xerr.WithStack(err)
+	}
+	return 0, errWrongType
+}
+
+// truncateInt converts a number to an integer, truncating its fraction
+func truncateInt(v interface{}) (int, error) {
+	switch v.(type) {
+	case float64, float32, int, int64, int32:
+		return xconv.ToInt(v), nil
+	}
+	return 0, errWrongType
+}
+
+// truncateInt64 converts a number or a string holding one to an integer, truncating its fraction
+func truncateInt64(v interface{}) (int64, error) {
+	if r, err := toInt64(v); err == nil {
+		return r, nil
+	}
+	f, err := toFloat64(v)
+	return int64(f), err
+}
+
+func toInt(v interface{}) (int, error) {
+	r, err := toInt64(v)
+	if err != nil {
+		return 0, err
+	}
+	if r < math.MinInt || r > math.MaxInt {
+		return 0, xerr.Errorf("%d overflows int", r)
+	}
+	return int(r), nil
+}
+
+func toInt64(v interface{}) (int64, error) {
+	switch val := v.(type) {
+	case int:
+		return int64(val), nil
+	case int64:
+		return val, nil
+	case int32:
+		return int64(val), nil
+	case string:
+		// parse integers directly, float64 cannot hold every int64
+		if r, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64); err == nil {
+			return r, nil
+		}
+	}
+
+	f, err := toFloat64(v)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, xerr.Errorf("%v is not an integer", f)
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, xerr.Errorf("%v overflows int64", f)
+	}
+	return int64(f), nil
+}
+
+// _timeLayouts are the layouts accepted by toTime, in order
+var _timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, nil
+	case string:
+		s := strings.TrimSpace(val)
+		for _, layout := range _timeLayouts {
+			if r, err := time.Parse(layout, s); err == nil {
+				return r, nil
 			}
 		}
+		return time.Time{}, xerr.Errorf("cannot parse '%s' as a time, expected RFC 3339 or 2006-01-02", s)
 	}
 
-	return nil
+	seconds, err := toInt64(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// toScalarString converts a string, number or boolean to a string
+func toScalarString(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case int, int64, int32:
+		return xconv.ToString(val), nil
+	}
+	return "", errWrongType
+}
+
+// lookupValue retrieves the value under key with its secret placeholders expanded, an empty key returns the whole configuration
+func lookupValue(key string, c MapConfiguration) (interface{}, error) {
+	segments, err := parsePath(key)
+	if err != nil {
+		return nil, &ConfigError{Key: key, Err: err}
+	}
+	for _, s := range segments {
+		if s.wildcard {
+			return nil, &ConfigError{Key: key, Err: xerr.New("wildcards are only supported by Query")}
+		}
+	}
+
+	v, _ := resolvePath(segments, c)
+	if v == nil {
+		return nil, nil
+	}
+	return expandSecrets(key, v)
 }
 
-// getNode retrieves a nested map node by key
-func getNode(key string, c map[string]interface{}) map[string]interface{} {
-	v, ok := c[key]
-	if ok {
-		return v.(map[string]interface{})
+// getValue retrieves a raw value from the configuration, nil if the key path is invalid or not found
+func getValue(key string, c MapConfiguration) interface{} {
+	segments, err := parsePath(key)
+	if err != nil {
+		return nil
 	}
-	return nil
+	v, _ := resolvePath(segments, c)
+	return v
 }
@@ -0,0 +1,203 @@
+package xconfig
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DreamvatLab/go/xerr"
+)
+
+// Configuration keys are paths of segments separated by dots:
+//
+//	Redis.Addr                     a key of a nested section
+//	servers.0.host                 a numeric segment indexes an array
+//	hosts."api.example.com".port   a quoted segment may hold dots, \" and \\ escape inside quotes
+//	hosts.api\.example\.com.port   a backslash escapes the next character outside quotes
+//	servers.*.host                 a * segment matches every entry of a section or array, see Query
+//
+// A segment is matched exactly against the keys of a section, then case-insensitively when no key
+// matches exactly, so "Redis.Addr" finds the "redis.addr" set by an environment variable.
+
+// pathSegment is a single segment of a parsed key path
+type pathSegment struct {
+	key      string
+	wildcard bool
+}
+
+// parsePath splits a key path into its segments, an empty path has no segments
+func parsePath(path string) ([]pathSegment, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	var (
+		r       []pathSegment
+		b       strings.Builder
+		literal bool // the segment was quoted or escaped, so "*" is not a wildcard
+		quoted  bool
+		closed  bool // the quoted segment was closed, only a dot may follow
+	)
+
+	endSegment := func() error {
+		if b.Len() == 0 && !literal {
+			return xerr.Errorf("invalid key path '%s': empty segment", path)
+		}
+		key := b.String()
+		r = append(r, pathSegment{key: key, wildcard: key == "*" && !literal})
+		b.Reset()
+		literal, closed = false, false
+		return nil
+	}
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '\\':
+			if i == len(path)-1 {
+				return nil, xerr.Errorf("invalid key path '%s': trailing backslash", path)
+			}
+			if closed {
+				return nil, xerr.Errorf("invalid key path '%s': unexpected character after quoted segment", path)
+			}
+			i++
+			b.WriteByte(path[i])
+			literal = true
+		case quoted:
+			if c == '"' {
+				quoted, closed = false, true
+			} else {
+				b.WriteByte(c)
+			}
+		case c == '.':
+			if err := endSegment(); err != nil {
+				return nil, err
+			}
+		case closed:
+			return nil, xerr.Errorf("invalid key path '%s': unexpected character after quoted segment", path)
+		case c == '"' && b.Len() == 0 && !literal:
+			quoted, literal = true, true
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	if quoted {
+		return nil, xerr.Errorf("invalid key path '%s': unterminated quote", path)
+	}
+	if err := endSegment(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// pathKeys splits a key path into the keys it addresses, for sources storing values under a path
+func pathKeys(path string) ([]string, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]string, 0, len(segments))
+	for _, s := range segments {
+		if s.wildcard {
+			return nil, xerr.Errorf("invalid key path '%s': wildcards cannot be assigned", path)
+		}
+		r = append(r, s.key)
+	}
+	return r, nil
+}
+
+// formatSegment returns key as a path segment, quoting it if needed
+func formatSegment(key string) string {
+	if key != "" && key != "*" && !strings.ContainsAny(key, `."\`) {
+		return key
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"`
+}
+
+// joinPath appends the key of a section entry to a path
+func joinPath(path, key string) string {
+	if path == "" {
+		return formatSegment(key)
+	}
+	return path + "." + formatSegment(key)
+}
+
+// resolvePath walks the segments down from v, wildcards match nothing
+func resolvePath(segments []pathSegment, v interface{}) (interface{}, bool) {
+	for _, s := range segments {
+		if s.wildcard {
+			return nil, false
+		}
+
+		var ok bool
+		v, _, ok = child(v, s.key)
+		if !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// child returns the entry of a section or the element of an array under key and the key it was found under
+func child(v interface{}, key string) (interface{}, string, bool) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		key = findKey(val, key)
+		r, ok := val[key]
+		return r, key, ok
+	case MapConfiguration:
+		key = findKey(val, key)
+		r, ok := val[key]
+		return r, key, ok
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(val) || strconv.Itoa(i) != key {
+			return nil, key, false
+		}
+		return val[i], key, true
+	}
+	return nil, key, false
+}
+
+// queryPath walks the segments down from v, calling match with the concrete path of every matching value.
+// Wildcards visit section entries in key order and array elements in index order.
+func queryPath(path string, segments []pathSegment, v interface{}, match func(path string, v interface{})) {
+	if len(segments) == 0 {
+		match(path, v)
+		return
+	}
+
+	s := segments[0]
+	if !s.wildcard {
+		if r, key, ok := child(v, s.key); ok {
+			queryPath(joinPath(path, key), segments[1:], r, match)
+		}
+		return
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		queryEntries(path, segments[1:], val, match)
+	case MapConfiguration:
+		queryEntries(path, segments[1:], val, match)
+	case []interface{}:
+		for i, e := range val {
+			queryPath(joinPath(path, strconv.Itoa(i)), segments[1:], e, match)
+		}
+	}
+}
+
+// queryEntries continues a query through every entry of a section, in key order
+func queryEntries(path string, segments []pathSegment, m map[string]interface{}, match func(path string, v interface{})) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		queryPath(joinPath(path, k), segments, m[k], match)
+	}
+}
@@ -0,0 +1,144 @@
+package xconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []string
+		wildcard int
+		wantErr  bool
+	}{
+		{"Redis.Addr", []string{"Redis", "Addr"}, -1, false},
+		{"servers.0.host", []string{"servers", "0", "host"}, -1, false},
+		{`hosts."api.example.com".port`, []string{"hosts", "api.example.com", "port"}, -1, false},
+		{`hosts.api\.example\.com`, []string{"hosts", "api.example.com"}, -1, false},
+		{`"say \"hi\""`, []string{`say "hi"`}, -1, false},
+		{`""`, []string{""}, -1, false},
+		{"servers.*.host", []string{"servers", "*", "host"}, 1, false},
+		{`servers."*"`, []string{"servers", "*"}, -1, false},
+		{"a..b", nil, -1, true},
+		{"a.", nil, -1, true},
+		{`"a`, nil, -1, true},
+		{`"a"b`, nil, -1, true},
+		{`a\`, nil, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			segments, err := parsePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			keys := make([]string, 0, len(segments))
+			for i, s := range segments {
+				keys = append(keys, s.key)
+				if s.wildcard != (i == tt.wildcard) {
+					t.Errorf("parsePath(%q) segment %d wildcard = %v", tt.path, i, s.wildcard)
+				}
+			}
+			if !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("parsePath(%q) = %q, want %q", tt.path, keys, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatSegment(t *testing.T) {
+	for _, key := range []string{"plain", "api.example.com", `say "hi"`, `back\slash`, "*", ""} {
+		segments, err := parsePath(joinPath("root", key))
+		if err != nil || len(segments) != 2 || segments[1].key != key || segments[1].wildcard {
+			t.Errorf("Key %q does not round-trip: %+v, %v", key, segments, err)
+		}
+	}
+}
+
+func TestMapConfiguration_Paths(t *testing.T) {
+	config := MapConfiguration{
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a", "port": float64(80)},
+			map[string]interface{}{"host": "b", "port": float64(81)},
+		},
+		"hosts": map[string]interface{}{
+			"api.example.com": map[string]interface{}{"timeout": "5s"},
+			"web.example.com": map[string]interface{}{"timeout": "10s"},
+		},
+	}
+
+	t.Run("Keys differing only in case", func(t *testing.T) {
+		config := MapConfiguration{"PORT": float64(1), "Port": float64(2), "port": float64(3)}
+		if v := config.GetInt("port"); v != 3 {
+			t.Errorf("Expected the exact match 3, got %d", v)
+		}
+		for i := 0; i < 10; i++ {
+			if v := config.GetInt("pOrt"); v != 1 {
+				t.Fatalf("Expected the lowest key PORT, got %d", v)
+			}
+		}
+	})
+
+	t.Run("Array indices", func(t *testing.T) {
+		if v := config.GetString("servers.1.host"); v != "b" {
+			t.Errorf("Expected 'b', got '%s'", v)
+		}
+		if config.Has("servers.2.host") || config.Has("servers.01.host") || config.Has("servers.host") {
+			t.Error("Expected out of range and non-numeric indices to be missing")
+		}
+	})
+
+	t.Run("Quoted and escaped keys", func(t *testing.T) {
+		if v := config.GetDuration(`hosts."api.example.com".timeout`); v.String() != "5s" {
+			t.Errorf("Expected 5s, got %v", v)
+		}
+		if v := config.GetString(`hosts.web\.example\.com.timeout`); v != "10s" {
+			t.Errorf("Expected '10s', got '%s'", v)
+		}
+	})
+
+	t.Run("GetStruct and Bind resolve the same paths", func(t *testing.T) {
+		var server struct {
+			Host string `config:"host"`
+			Port int    `config:"port"`
+		}
+		if err := config.GetStruct("servers.1", &server); err != nil || server.Host != "b" {
+			t.Errorf("Expected server b, got %+v, %v", server, err)
+		}
+		if err := config.Bind("servers.0", &server); err != nil || server.Port != 80 {
+			t.Errorf("Expected port 80, got %+v, %v", server, err)
+		}
+
+		var host struct{ Timeout string }
+		if err := config.GetStruct(`hosts."api.example.com"`, &host); err != nil || host.Timeout != "5s" {
+			t.Errorf("Expected timeout 5s, got %+v, %v", host, err)
+		}
+	})
+
+	t.Run("Query with wildcards", func(t *testing.T) {
+		hosts, err := config.Query("servers.*.host")
+		if err != nil || !reflect.DeepEqual(hosts, []interface{}{"a", "b"}) {
+			t.Errorf("Expected [a b], got %v, %v", hosts, err)
+		}
+
+		timeouts, err := config.Query("hosts.*.timeout")
+		if err != nil || !reflect.DeepEqual(timeouts, []interface{}{"5s", "10s"}) {
+			t.Errorf("Expected [5s 10s], got %v, %v", timeouts, err)
+		}
+
+		if _, err := config.Query("servers..host"); err == nil {
+			t.Error("Expected error for invalid path")
+		}
+	})
+
+	t.Run("Wildcards are rejected by getters", func(t *testing.T) {
+		if _, _, err := config.LookupString("servers.*.host"); err == nil {
+			t.Error("Expected error for wildcard lookup")
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package xconfig
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// RedactedValue replaces sensitive values in a redacted configuration, as in the redacted log entries
+const RedactedValue = xlog.RedactedValue
+
+// Redact returns a copy of the configuration safe to print.
+//
+// Values under a key holding one of xlog.DefaultRedactedFields (password, secret, token, apikey...) or
+// one of the extra keys are replaced by RedactedValue, as are encrypted values and URL passwords.
+// Keys are matched on whole words as log fields are, so "user_password" is redacted but "tokenizer" is not.
+// Secret placeholders are kept as they only name where the secret comes from.
+func Redact(c MapConfiguration, keys ...string) MapConfiguration {
+	sensitive := xlog.NewFieldMatcher(append(append([]string{}, xlog.DefaultRedactedFields...), keys...)...)
+
+	r, _ := redactValue(map[string]interface{}(c), sensitive).(map[string]interface{})
+	return r
+}
+
+// redactValue returns a redacted copy of v
+func redactValue(v interface{}, sensitive xlog.FieldMatcher) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		r := make(map[string]interface{}, len(val))
+		for key, e := range val {
+			if sensitive.Match(key) && !isSection(e) && !isPlaceholder(e) {
+				r[key] = RedactedValue
+				continue
+			}
+			r[key] = redactValue(e, sensitive)
+		}
+		return r
+	case MapConfiguration:
+		return redactValue(map[string]interface{}(val), sensitive)
+	case []interface{}:
+		r := make([]interface{}, len(val))
+		for i, e := range val {
+			r[i] = redactValue(e, sensitive)
+		}
+		return r
+	case string:
+		return redactString(val)
+	}
+	return v
+}
+
+// isSection reports whether v is a section, whose entries are redacted one by one
+func isSection(v interface{}) bool {
+	_, err := toMap(v)
+	return err == nil || v == nil
+}
+
+// isPlaceholder reports whether v is a string made of ${env:...} and ${file:...} placeholders,
+// which never hold the secret itself
+func isPlaceholder(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && s != "" && !_encryptedRegex.MatchString(s) && _placeholderRegex.ReplaceAllString(s, "") == ""
+}
+
+// redactString hides encrypted values and the password of URLs such as "redis://:pass@host"
+func redactString(s string) string {
+	s = _encryptedRegex.ReplaceAllLiteralString(s, RedactedValue)
+	if !strings.Contains(s, "://") || hasSecret(s) {
+		return s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, hasPassword := u.User.Password(); !hasPassword {
+		return s
+	}
+	// url.UserPassword would escape the brackets of RedactedValue
+	userInfo := url.User(u.User.Username()).String() + ":" + RedactedValue + "@"
+	u.User = nil
+	return strings.Replace(u.String(), "//", "//"+userInfo, 1)
+}
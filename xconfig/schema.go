@@ -0,0 +1,499 @@
+package xconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DreamvatLab/go/xerr"
+)
+
+// SchemaVersion is the JSON Schema dialect of the generated schemas
+const SchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// Formats of the values bound to time.Duration and ByteSize fields, which accept a string or a number
+const (
+	DurationFormat = "go-duration"
+	ByteSizeFormat = "byte-size"
+)
+
+// Schema is the subset of JSON Schema generated by GenerateSchema and checked by Validate.
+//
+// Minimum and Maximum of durations and sizes are expressed in nanoseconds and bytes.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// SchemaType lists the JSON types a value may have, it is written as a single string when it holds one type
+type SchemaType []string
+
+func (x SchemaType) MarshalJSON() ([]byte, error) {
+	if len(x) == 1 {
+		return json.Marshal(x[0])
+	}
+	return json.Marshal([]string(x))
+}
+
+func (x *SchemaType) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*x = SchemaType{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(x))
+}
+
+// has reports whether values of type t are allowed, an empty list allows any type
+func (x SchemaType) has(t string) bool {
+	return len(x) == 0 || contains(x, t)
+}
+
+// LoadSchema reads a schema written by GenerateSchema
+func LoadSchema(data []byte) (*Schema, error) {
+	r := new(Schema)
+	err := json.Unmarshal(data, r)
+	if err != nil {
+		return nil, xerr.WithStack(err)
+	}
+	return r, nil
+}
+
+// GenerateSchema derives a JSON Schema from the struct target points to, following the tags Bind reads:
+// field names come from `config`, `json` or the field name, `default` becomes the default value and the
+// required, min, max and oneof `validate` rules become the matching schema keywords.
+func GenerateSchema(target interface{}) (*Schema, error) {
+	t := reflect.TypeOf(target)
+	if t == nil || indirectType(t).Kind() != reflect.Struct {
+		return nil, xerr.Errorf("schema target must be a struct or a pointer to a struct, got %T", target)
+	}
+
+	r, err := schemaOf(indirectType(t), make(map[reflect.Type]bool))
+	if err != nil {
+		return nil, err
+	}
+	r.Schema = SchemaVersion
+	return r, nil
+}
+
+// schemaOf returns the schema of a Go type, visiting guards against recursive types
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) (*Schema, error) {
+	t = indirectType(t)
+
+	switch t {
+	case _durationType:
+		return &Schema{Type: SchemaType{"string", "integer"}, Format: DurationFormat}, nil
+	case _byteSizeType:
+		return &Schema{Type: SchemaType{"string", "integer"}, Format: ByteSizeFormat}, nil
+	case _timeType:
+		return &Schema{Type: SchemaType{"string", "integer"}, Format: "date-time"}, nil
+	}
+	if reflect.PointerTo(t).Implements(_unmarshalerType) {
+		return &Schema{Type: SchemaType{"string"}}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}, nil
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: SchemaType{"integer"}}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: SchemaType{"integer"}, Minimum: &zero}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := schemaOf(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: SchemaType{"array"}, Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, xerr.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := schemaOf(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: SchemaType{"object"}, AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if visiting[t] {
+			// recursive types are described once, the nested occurrence accepts any object
+			return &Schema{Type: SchemaType{"object"}}, nil
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		r := &Schema{Type: SchemaType{"object"}, Properties: make(map[string]*Schema)}
+		if err := addProperties(r, t, visiting); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+
+	return nil, xerr.Errorf("unsupported field type %s", t)
+}
+
+// addProperties adds the fields of the struct type t to the object schema r, embedded structs share the keys of their parent
+func addProperties(r *Schema, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == field.Name && indirectType(field.Type).Kind() == reflect.Struct {
+			if err := addProperties(r, indirectType(field.Type), visiting); err != nil {
+				return err
+			}
+			continue
+		}
+
+		s, err := schemaOf(field.Type, visiting)
+		if err != nil {
+			return xerr.WithMessage(err, "field "+t.Name()+"."+field.Name)
+		}
+
+		defaultValue, hasDefault := field.Tag.Lookup("default")
+		if hasDefault {
+			s.Default = schemaValue(s, defaultValue)
+		}
+
+		rules := parseRules(field.Tag.Get("validate"))
+		if _, required := rules["required"]; required && !hasDefault {
+			r.Required = append(r.Required, name)
+		}
+		if err := applyRules(s, indirectType(field.Type), rules); err != nil {
+			return xerr.WithMessage(err, "field "+t.Name()+"."+field.Name)
+		}
+
+		r.Properties[name] = s
+	}
+	return nil
+}
+
+// applyRules translates the min, max and oneof rules of a field into schema keywords
+func applyRules(s *Schema, t reflect.Type, rules map[string]string) error {
+	if oneOf, ok := rules["oneof"]; ok {
+		for _, e := range strings.Fields(oneOf) {
+			s.Enum = append(s.Enum, schemaValue(s, e))
+		}
+	}
+
+	for _, rule := range []string{"min", "max"} {
+		limit, ok := rules[rule]
+		if !ok {
+			continue
+		}
+
+		var bound float64
+		var err error
+		switch t {
+		case _durationType:
+			var d time.Duration
+			d, err = time.ParseDuration(limit)
+			bound = float64(d)
+		case _byteSizeType:
+			var size ByteSize
+			size, err = ParseByteSize(limit)
+			bound = float64(size)
+		default:
+			bound, err = strconv.ParseFloat(limit, 64)
+		}
+		if err != nil {
+			return xerr.Wrapf(err, "invalid %s rule", rule)
+		}
+
+		isMin := rule == "min"
+		switch {
+		case s.Type.has("integer") || s.Type.has("number"):
+			if isMin {
+				s.Minimum = &bound
+			} else {
+				s.Maximum = &bound
+			}
+		case t.Kind() == reflect.String:
+			setLimit(&s.MinLength, &s.MaxLength, isMin, bound)
+		case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+			setLimit(&s.MinItems, &s.MaxItems, isMin, bound)
+		case t.Kind() == reflect.Map:
+			setLimit(&s.MinProperties, &s.MaxProperties, isMin, bound)
+		default:
+			return xerr.Errorf("%s rule not supported for %s", rule, t)
+		}
+	}
+	return nil
+}
+
+// setLimit sets the min or max count keyword
+func setLimit(min, max **int, isMin bool, bound float64) {
+	n := int(bound)
+	if isMin {
+		*min = &n
+	} else {
+		*max = &n
+	}
+}
+
+// schemaValue converts a tag value to the JSON type of the schema, as Bind would convert it
+func schemaValue(s *Schema, value string) interface{} {
+	switch {
+	case s.Format != "":
+		return value
+	case s.Type.has("integer") && len(s.Type) == 1:
+		if r, err := toInt64(value); err == nil {
+			return r
+		}
+	case s.Type.has("number") && len(s.Type) == 1:
+		if r, err := toFloat64(value); err == nil {
+			return r
+		}
+	case s.Type.has("boolean") && len(s.Type) == 1:
+		if r, err := toBool(value); err == nil {
+			return r
+		}
+	case s.Type.has("array") && len(s.Type) == 1 && s.Items != nil:
+		r := make([]interface{}, 0)
+		for _, item := range strings.Split(value, ",") {
+			r = append(r, schemaValue(s.Items, strings.TrimSpace(item)))
+		}
+		return r
+	}
+	return value
+}
+
+// Validate checks a configuration against the schema, reporting every invalid value as in Bind.
+// Values holding secret placeholders are not checked, since they are only known once expanded.
+func (x *Schema) Validate(c MapConfiguration) error {
+	var errs ConfigErrors
+	x.validate("", map[string]interface{}(c), &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validate checks v against the schema, appending any problem to errs
+func (x *Schema) validate(path string, v interface{}, errs *ConfigErrors) {
+	if s, ok := v.(string); ok && hasSecret(s) {
+		return
+	}
+
+	measured, err := x.check(v)
+	if err != nil {
+		err.Key = path
+		*errs = append(*errs, err)
+		return
+	}
+
+	if len(x.Enum) > 0 {
+		allowed := make([]string, 0, len(x.Enum))
+		for _, e := range x.Enum {
+			allowed = append(allowed, fmt.Sprint(e))
+		}
+		if s := fmt.Sprint(v); !contains(allowed, s) {
+			*errs = append(*errs, &ConfigError{Key: path, Expected: "one of [" + strings.Join(allowed, " ") + "]", Err: xerr.Errorf("got %q", s)})
+			return
+		}
+	}
+
+	if err := x.checkLimits(v, measured); err != nil {
+		err.Key = path
+		*errs = append(*errs, err)
+		return
+	}
+
+	switch val := v.(type) {
+	case []interface{}:
+		if x.Items != nil {
+			for i, e := range val {
+				x.Items.validate(joinPath(path, strconv.Itoa(i)), e, errs)
+			}
+		}
+	case map[string]interface{}:
+		x.validateProperties(path, val, errs)
+	case MapConfiguration:
+		x.validateProperties(path, val, errs)
+	}
+}
+
+// validateProperties checks the entries of a section
+func (x *Schema) validateProperties(path string, m map[string]interface{}, errs *ConfigErrors) {
+	names := make([]string, 0, len(x.Properties))
+	for name := range x.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matched := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := findKey(m, name)
+		matched[key] = true
+
+		value, found := m[key]
+		if !found || value == nil {
+			if contains(x.Required, name) {
+				*errs = append(*errs, &ConfigError{Key: joinPath(path, name), Expected: x.Properties[name].Type.String(), Err: xerr.New("is required")})
+			}
+			continue
+		}
+		x.Properties[name].validate(joinPath(path, name), value, errs)
+	}
+
+	if x.AdditionalProperties == nil {
+		return
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		if !matched[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		x.AdditionalProperties.validate(joinPath(path, key), m[key], errs)
+	}
+}
+
+// check verifies the type of v, returning the number min/max rules compare for numbers, durations and sizes
+func (x *Schema) check(v interface{}) (float64, *ConfigError) {
+	switch x.Format {
+	case DurationFormat:
+		d, err := toDuration(v)
+		if err != nil {
+			return 0, newConvertError("", "duration", v, err)
+		}
+		return float64(d), nil
+	case ByteSizeFormat:
+		size, err := toByteSize(v)
+		if err != nil {
+			return 0, newConvertError("", "byte size", v, err)
+		}
+		return float64(size), nil
+	case "date-time":
+		_, err := toTime(v)
+		if err != nil {
+			return 0, newConvertError("", "time", v, err)
+		}
+		return 0, nil
+	}
+
+	if len(x.Type) == 0 {
+		return 0, nil
+	}
+
+	// strings are accepted wherever Bind converts them, e.g. numbers coming from environment variables
+	var lastErr *ConfigError
+	for _, t := range x.Type {
+		var n float64
+		var err error
+		switch t {
+		case "integer":
+			var i int64
+			i, err = toInt64(v)
+			n = float64(i)
+		case "number":
+			n, err = toFloat64(v)
+		case "boolean":
+			_, err = toBool(v)
+		case "string":
+			switch v.(type) {
+			case string, float64, bool:
+			default:
+				err = errWrongType
+			}
+		case "array":
+			if _, ok := v.([]interface{}); !ok {
+				err = errWrongType
+			}
+		case "object":
+			_, err = toMap(v)
+		}
+		if err == nil {
+			return n, nil
+		}
+		lastErr = newConvertError("", x.Type.String(), v, err)
+	}
+	return 0, lastErr
+}
+
+// checkLimits verifies the minimum, maximum and length keywords
+func (x *Schema) checkLimits(v interface{}, measured float64) *ConfigError {
+	if x.Minimum != nil && measured < *x.Minimum {
+		return &ConfigError{Err: xerr.Errorf("must be >= %s, got %v", x.formatLimit(*x.Minimum), v)}
+	}
+	if x.Maximum != nil && measured > *x.Maximum {
+		return &ConfigError{Err: xerr.Errorf("must be <= %s, got %v", x.formatLimit(*x.Maximum), v)}
+	}
+
+	var length int
+	var min, max *int
+	switch val := v.(type) {
+	case string:
+		length, min, max = len(val), x.MinLength, x.MaxLength
+	case []interface{}:
+		length, min, max = len(val), x.MinItems, x.MaxItems
+	case map[string]interface{}:
+		length, min, max = len(val), x.MinProperties, x.MaxProperties
+	}
+	if min != nil && length < *min {
+		return &ConfigError{Err: xerr.Errorf("length must be >= %d, got %d", *min, length)}
+	}
+	if max != nil && length > *max {
+		return &ConfigError{Err: xerr.Errorf("length must be <= %d, got %d", *max, length)}
+	}
+	return nil
+}
+
+// formatLimit prints a bound in the unit of the schema
+func (x *Schema) formatLimit(bound float64) string {
+	switch x.Format {
+	case DurationFormat:
+		return time.Duration(bound).String()
+	case ByteSizeFormat:
+		return ByteSize(bound).String()
+	}
+	return strconv.FormatFloat(bound, 'f', -1, 64)
+}
+
+func (x SchemaType) String() string {
+	if len(x) == 0 {
+		return "any"
+	}
+	return strings.Join(x, " or ")
+}
+
+// hasSecret reports whether s holds a secret placeholder or an encrypted value
+func hasSecret(s string) bool {
+	return _placeholderRegex.MatchString(s)
+}
@@ -0,0 +1,204 @@
+package xconfig
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DreamvatLab/go/xerr"
+	"github.com/DreamvatLab/go/xsecurity"
+)
+
+// Secret placeholders are expanded whenever a value is read, so secrets never need to be
+// written to configuration files in clear text:
+//
+//	${env:REDIS_PASS}             value of an environment variable, an error if it is not set
+//	${env:REDIS_PASS:-default}    value of an environment variable with a fallback
+//	${file:/run/secrets/key}      content of a file, without the trailing newline
+//	${enc:BASE64}                 a value decrypted with the encryptor set by SetSecretEncryptor
+//
+// Placeholders may be embedded in a longer string such as "redis://:${env:REDIS_PASS}@host".
+// Providers check every placeholder when they load, so a missing secret fails at startup.
+// Files are read when a provider loads and decrypted values are cached, so reading a value stays cheap.
+
+var (
+	_placeholderRegex = regexp.MustCompile(`\$\{(env|file|enc):([^}]*)\}`)
+	_encryptedRegex   = regexp.MustCompile(`\$\{enc:[^}]*\}`)
+
+	_secretMutex     sync.RWMutex
+	_secretEncryptor xsecurity.IEncryptor
+	_decryptedCache  sync.Map
+	// _fileCache holds the content of the secret files by path, read again at every load
+	_fileCache sync.Map
+)
+
+// SetSecretEncryptor sets the encryptor used to decrypt ${enc:...} values, e.g. an xsecurity.RSAEncryptor.
+// It must be called before loading a configuration holding encrypted values.
+func SetSecretEncryptor(encryptor xsecurity.IEncryptor) {
+	_secretMutex.Lock()
+	defer _secretMutex.Unlock()
+
+	_secretEncryptor = encryptor
+	_decryptedCache.Range(func(key, _ interface{}) bool {
+		_decryptedCache.Delete(key)
+		return true
+	})
+}
+
+// expandSecrets returns v with every string holding a secret placeholder expanded.
+// Maps and slices are copied only when something inside them changed, the configuration itself is never modified.
+func expandSecrets(path string, v interface{}) (interface{}, error) {
+	r, _, err := expandValue(path, v, false)
+	return r, err
+}
+
+// expandValue expands the placeholders in v, reporting whether anything changed.
+// readFiles reads the secret files again instead of using their cached content.
+func expandValue(path string, v interface{}, readFiles bool) (interface{}, bool, error) {
+	switch val := v.(type) {
+	case string:
+		r, err := expandString(val, readFiles)
+		if err != nil {
+			return nil, false, &ConfigError{Key: path, Err: err}
+		}
+		return r, r != val, nil
+	case MapConfiguration:
+		r, changed, err := expandValue(path, map[string]interface{}(val), readFiles)
+		if err != nil || !changed {
+			return val, false, err
+		}
+		return MapConfiguration(r.(map[string]interface{})), true, nil
+	case map[string]interface{}:
+		var r map[string]interface{}
+		for key, e := range val {
+			expanded, changed, err := expandValue(joinPath(path, key), e, readFiles)
+			if err != nil {
+				return nil, false, err
+			}
+			if changed && r == nil {
+				r = make(map[string]interface{}, len(val))
+				for k, old := range val {
+					r[k] = old
+				}
+			}
+			if r != nil {
+				r[key] = expanded
+			}
+		}
+		if r == nil {
+			return val, false, nil
+		}
+		return r, true, nil
+	case []interface{}:
+		var r []interface{}
+		for i, e := range val {
+			expanded, changed, err := expandValue(joinPath(path, strconv.Itoa(i)), e, readFiles)
+			if err != nil {
+				return nil, false, err
+			}
+			if changed && r == nil {
+				r = make([]interface{}, len(val))
+				copy(r, val)
+			}
+			if r != nil {
+				r[i] = expanded
+			}
+		}
+		if r == nil {
+			return val, false, nil
+		}
+		return r, true, nil
+	}
+	return v, false, nil
+}
+
+// checkSecrets expands every placeholder of a freshly loaded configuration to report missing secrets early,
+// reading the secret files again
+func checkSecrets(m MapConfiguration) error {
+	_, _, err := expandValue("", map[string]interface{}(m), true)
+	return err
+}
+
+// expandString expands the placeholders of a single value
+func expandString(s string, readFiles bool) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var firstErr error
+	r := _placeholderRegex.ReplaceAllStringFunc(s, func(placeholder string) string {
+		match := _placeholderRegex.FindStringSubmatch(placeholder)
+		value, err := resolvePlaceholder(match[1], match[2], readFiles)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return value
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return r, nil
+}
+
+// resolvePlaceholder returns the value of a single ${scheme:argument} placeholder
+func resolvePlaceholder(scheme, argument string, readFiles bool) (string, error) {
+	switch scheme {
+	case "env":
+		name, defaultValue, hasDefault := strings.Cut(argument, ":-")
+		if value, ok := os.LookupEnv(name); ok {
+			return value, nil
+		}
+		if hasDefault {
+			return defaultValue, nil
+		}
+		return "", xerr.Errorf("environment variable %s is not set", name)
+	case "file":
+		return readSecretFile(argument, readFiles)
+	case "enc":
+		return decryptSecret(argument)
+	}
+	return "", xerr.Errorf("unknown placeholder scheme: %s", scheme)
+}
+
+// readSecretFile returns the content of a secret file without the trailing newline,
+// from the cache unless readFiles is set or the file was never read
+func readSecretFile(path string, readFiles bool) (string, error) {
+	if !readFiles {
+		if r, ok := _fileCache.Load(path); ok {
+			return r.(string), nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", xerr.WithStack(err)
+	}
+	r := strings.TrimRight(string(data), "\r\n")
+	_fileCache.Store(path, r)
+	return r, nil
+}
+
+// decryptSecret decrypts an ${enc:...} value, caching the result as decryption can be expensive
+func decryptSecret(cipherText string) (string, error) {
+	if r, ok := _decryptedCache.Load(cipherText); ok {
+		return r.(string), nil
+	}
+
+	_secretMutex.RLock()
+	encryptor := _secretEncryptor
+	_secretMutex.RUnlock()
+
+	if encryptor == nil {
+		return "", xerr.New("encrypted value found but no secret encryptor is set, call xconfig.SetSecretEncryptor first")
+	}
+
+	r, err := encryptor.DecryptString(cipherText)
+	if err != nil {
+		return "", xerr.Wrap(err, "decrypt secret")
+	}
+
+	_decryptedCache.Store(cipherText, r)
+	return r, nil
+}
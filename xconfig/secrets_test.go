@@ -0,0 +1,117 @@
+package xconfig
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DreamvatLab/go/xsecurity"
+)
+
+func TestSecretPlaceholders(t *testing.T) {
+	encryptor := xsecurity.CreateTripleDESEncryptor("0123456789abcdefghijklmn")
+	SetSecretEncryptor(encryptor)
+	defer SetSecretEncryptor(nil)
+
+	cipherText, err := encryptor.EncryptString("3des-secret")
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	dir := t.TempDir()
+	keyFile := writeTempConfig(t, dir, "key", "file-secret\n")
+	t.Setenv("XCFGTEST_REDIS_PASS", "env-secret")
+
+	path := writeTempConfig(t, dir, "configs.json", `{
+		"Redis": {
+			"Password": "${env:XCFGTEST_REDIS_PASS}",
+			"Url": "redis://:${env:XCFGTEST_REDIS_PASS}@localhost:6379",
+			"User": "${env:XCFGTEST_MISSING:-default}"
+		},
+		"Key": "${file:`+filepath.ToSlash(keyFile)+`}",
+		"Encrypted": "${enc:`+cipherText+`}",
+		"List": ["${env:XCFGTEST_REDIS_PASS}", "plain"]
+	}`)
+
+	provider, err := LoadJsonConfigProvider(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Getters expand placeholders", func(t *testing.T) {
+		expected := map[string]string{
+			"Redis.Password": "env-secret",
+			"Redis.Url":      "redis://:env-secret@localhost:6379",
+			"Redis.User":     "default",
+			"Key":            "file-secret",
+			"Encrypted":      "3des-secret",
+		}
+		for key, value := range expected {
+			if v := provider.GetString(key); v != value {
+				t.Errorf("%s: expected '%s', got '%s'", key, value, v)
+			}
+		}
+		if v := provider.GetStringSlice("List"); len(v) != 2 || v[0] != "env-secret" {
+			t.Errorf("Expected expanded list, got %v", v)
+		}
+	})
+
+	t.Run("GetStruct and Bind expand placeholders", func(t *testing.T) {
+		var redis struct {
+			Password string
+			Url      string
+		}
+		if err := provider.GetStruct("Redis", &redis); err != nil || redis.Password != "env-secret" {
+			t.Errorf("Expected expanded password, got %+v, %v", redis, err)
+		}
+
+		redis.Password = ""
+		if err := provider.Bind("Redis", &redis); err != nil || redis.Password != "env-secret" {
+			t.Errorf("Expected expanded password, got %+v, %v", redis, err)
+		}
+	})
+
+	t.Run("Raw configuration keeps placeholders", func(t *testing.T) {
+		raw := string(provider.(*JsonConfigProvider).RawJson)
+		if strings.Contains(raw, "env-secret") {
+			t.Error("Expected raw configuration to keep placeholders")
+		}
+	})
+
+	t.Run("Missing secret fails at load", func(t *testing.T) {
+		path := writeTempConfig(t, dir, "missing.json", `{"Redis": {"Password": "${env:XCFGTEST_MISSING}"}}`)
+		_, err := LoadJsonConfigProvider(path)
+		if err == nil || !strings.Contains(err.Error(), "Redis.Password") {
+			t.Errorf("Expected error naming Redis.Password, got %v", err)
+		}
+	})
+
+	t.Run("Encrypted value without encryptor", func(t *testing.T) {
+		SetSecretEncryptor(nil)
+		config := MapConfiguration{"Encrypted": "${enc:" + cipherText + "}"}
+		if _, _, err := config.LookupString("Encrypted"); err == nil {
+			t.Error("Expected error without encryptor")
+		}
+	})
+
+	t.Run("Secret files are read at load", func(t *testing.T) {
+		SetSecretEncryptor(encryptor)
+		writeTempConfig(t, dir, "key", "rotated-secret\n")
+		if v := provider.GetString("Key"); v != "file-secret" {
+			t.Errorf("Expected the content read at load, got '%s'", v)
+		}
+		if _, err := LoadJsonConfigProvider(path); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if v := provider.GetString("Key"); v != "rotated-secret" {
+			t.Errorf("Expected the content read by the last load, got '%s'", v)
+		}
+	})
+
+	t.Run("Plain values are not decrypted", func(t *testing.T) {
+		config := MapConfiguration{"Value": "enc:plain text"}
+		if v, _, err := config.LookupString("Value"); err != nil || v != "enc:plain text" {
+			t.Errorf("Expected the value unchanged, got '%s', %v", v, err)
+		}
+	})
+}
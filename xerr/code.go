@@ -0,0 +1,187 @@
+package xerr
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Category classifies errors by the way callers handle them, whatever the service
+type Category string
+
+// 错误类别
+const (
+	CategoryUnknown            Category = "unknown"
+	CategoryInvalidArgument    Category = "invalid_argument"
+	CategoryNotFound           Category = "not_found"
+	CategoryAlreadyExists      Category = "already_exists"
+	CategoryPermissionDenied   Category = "permission_denied"
+	CategoryUnauthenticated    Category = "unauthenticated"
+	CategoryFailedPrecondition Category = "failed_precondition"
+	CategoryResourceExhausted  Category = "resource_exhausted"
+	CategoryUnavailable        Category = "unavailable"
+	CategoryDeadlineExceeded   Category = "deadline_exceeded"
+	CategoryInternal           Category = "internal"
+)
+
+// _defaultUserMessage is the message shown to users for the errors without a CodedError
+const _defaultUserMessage = "internal error"
+
+// HTTPStatus returns the HTTP status code matching the category
+func (o Category) HTTPStatus() int {
+	switch o {
+	case CategoryInvalidArgument:
+		return http.StatusBadRequest
+	case CategoryNotFound:
+		return http.StatusNotFound
+	case CategoryAlreadyExists:
+		return http.StatusConflict
+	case CategoryPermissionDenied:
+		return http.StatusForbidden
+	case CategoryUnauthenticated:
+		return http.StatusUnauthorized
+	case CategoryFailedPrecondition:
+		return http.StatusPreconditionFailed
+	case CategoryResourceExhausted:
+		return http.StatusTooManyRequests
+	case CategoryUnavailable:
+		return http.StatusServiceUnavailable
+	case CategoryDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// CodedError is an error identified by a code, e.g. "user_not_found", usually declared once as a sentinel:
+//
+//	var ErrUserNotFound = xerr.NewCoded("user_not_found", xerr.CategoryNotFound, "the user does not exist")
+//
+//	return xerr.WithStack(ErrUserNotFound.WithDetail("id %d", id).WithMeta("id", id))
+//
+// Errors with the same code match with Is. The message is safe to show to users, the detail is internal.
+// A CodedError is immutable, WithDetail, WithMeta and Wrap return copies.
+type CodedError struct {
+	code     string
+	category Category
+	message  string
+	detail   string
+	metadata map[string]interface{}
+	cause    error
+}
+
+// NewCoded creates a CodedError with a code, a category and a message safe to show to users
+func NewCoded(code string, category Category, message string) *CodedError {
+	if category == "" {
+		category = CategoryUnknown
+	}
+	return &CodedError{code: code, category: category, message: message}
+}
+
+// Code returns the code of the error
+func (o *CodedError) Code() string {
+	return o.code
+}
+
+// Category returns the category of the error
+func (o *CodedError) Category() Category {
+	return o.category
+}
+
+// Message returns the message safe to show to users
+func (o *CodedError) Message() string {
+	return o.message
+}
+
+// Detail returns the internal detail of the error
+func (o *CodedError) Detail() string {
+	return o.detail
+}
+
+// Metadata returns a copy of the metadata of the error
+func (o *CodedError) Metadata() map[string]interface{} {
+	r := make(map[string]interface{}, len(o.metadata))
+	for k, v := range o.metadata {
+		r[k] = v
+	}
+	return r
+}
+
+// WithDetail returns a copy of the error with the formatted internal detail, which is not shown to users
+func (o *CodedError) WithDetail(format string, args ...interface{}) *CodedError {
+	r := *o
+	r.detail = fmt.Sprintf(format, args...)
+	return &r
+}
+
+// WithMeta returns a copy of the error with a metadata entry
+func (o *CodedError) WithMeta(key string, value interface{}) *CodedError {
+	r := *o
+	r.metadata = o.Metadata()
+	r.metadata[key] = value
+	return &r
+}
+
+// Wrap returns a copy of the error caused by cause, returned by Unwrap
+func (o *CodedError) Wrap(cause error) *CodedError {
+	r := *o
+	r.cause = cause
+	return &r
+}
+
+// Error returns the code, the message, the detail and the cause
+func (o *CodedError) Error() string {
+	r := o.code + ": " + o.message
+	if o.detail != "" {
+		r += ": " + o.detail
+	}
+	if o.cause != nil {
+		r += ": " + o.cause.Error()
+	}
+	return r
+}
+
+// Unwrap returns the cause of the error
+func (o *CodedError) Unwrap() error {
+	return o.cause
+}
+
+// Is reports whether target is a CodedError with the same code
+func (o *CodedError) Is(target error) bool {
+	t, ok := target.(*CodedError)
+	return ok && t.code == o.code
+}
+
+// Coded returns the first CodedError of the chain of err
+func Coded(err error) (*CodedError, bool) {
+	var r *CodedError
+	if errors.As(err, &r) {
+		return r, true
+	}
+	return nil, false
+}
+
+// Code returns the code of the first CodedError of the chain of err, or an empty string
+func Code(err error) string {
+	if r, ok := Coded(err); ok {
+		return r.code
+	}
+	return ""
+}
+
+// CategoryOf returns the category of the first CodedError of the chain of err, CategoryUnknown if there is none
+func CategoryOf(err error) Category {
+	if r, ok := Coded(err); ok {
+		return r.category
+	}
+	return CategoryUnknown
+}
+
+// UserMessage returns the message of the first CodedError of the chain of err, safe to show to users,
+// or a generic message hiding the internal errors
+func UserMessage(err error) string {
+	if r, ok := Coded(err); ok && r.message != "" {
+		return r.message
+	}
+	return _defaultUserMessage
+}
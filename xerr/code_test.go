@@ -0,0 +1,70 @@
+package xerr
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+var errUserNotFound = NewCoded("user_not_found", CategoryNotFound, "the user does not exist")
+
+func TestCodedError(t *testing.T) {
+	cause := New("no rows")
+	err := Wrap(errUserNotFound.WithDetail("id %d", 7).WithMeta("id", 7).Wrap(cause), "loading the profile")
+
+	t.Run("Extraction through wrap chains", func(t *testing.T) {
+		if code := Code(err); code != "user_not_found" {
+			t.Errorf("Expected user_not_found, got %q", code)
+		}
+		if category := CategoryOf(fmt.Errorf("handler: %w", err)); category != CategoryNotFound {
+			t.Errorf("Expected %s, got %s", CategoryNotFound, category)
+		}
+		coded, ok := Coded(err)
+		if !ok || coded.Detail() != "id 7" || coded.Metadata()["id"] != 7 {
+			t.Errorf("Unexpected coded error %+v", coded)
+		}
+		if Code(cause) != "" || CategoryOf(cause) != CategoryUnknown {
+			t.Error("Expected no code for a plain error")
+		}
+	})
+
+	t.Run("Is matches by code", func(t *testing.T) {
+		if !Is(err, errUserNotFound) {
+			t.Error("Expected the wrapped error to match the sentinel")
+		}
+		if !Is(err, cause) {
+			t.Error("Expected the error to match its cause")
+		}
+		if Is(err, NewCoded("order_not_found", CategoryNotFound, "")) {
+			t.Error("Expected no match for another code")
+		}
+	})
+
+	t.Run("Messages", func(t *testing.T) {
+		expected := "loading the profile: user_not_found: the user does not exist: id 7: no rows"
+		if err.Error() != expected {
+			t.Errorf("Expected %q, got %q", expected, err.Error())
+		}
+		if msg := UserMessage(err); msg != "the user does not exist" {
+			t.Errorf("Expected the user message, got %q", msg)
+		}
+		if msg := UserMessage(cause); msg != _defaultUserMessage {
+			t.Errorf("Expected %q, got %q", _defaultUserMessage, msg)
+		}
+	})
+
+	t.Run("Copies", func(t *testing.T) {
+		if errUserNotFound.Detail() != "" || len(errUserNotFound.Metadata()) != 0 || errUserNotFound.Unwrap() != nil {
+			t.Error("Expected the sentinel unchanged")
+		}
+	})
+
+	t.Run("HTTP status", func(t *testing.T) {
+		if s := CategoryOf(err).HTTPStatus(); s != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", s)
+		}
+		if s := CategoryOf(cause).HTTPStatus(); s != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d", s)
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package xlog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what an AsyncSink does with an entry when its queue is full
+type OverflowPolicy string
+
+// 队列满时的处理策略
+const (
+	// OverflowBlock makes the caller wait for room in the queue, no entry is lost
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropNewest drops the entry being logged
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest drops the oldest queued entry to make room for the new one
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+)
+
+// 异步队列默认值
+const (
+	DefaultQueueSize = 1024
+	DefaultBatchSize = 64
+)
+
+// AsyncSinkConfig configures the queue of an AsyncSink
+type AsyncSinkConfig struct {
+	// QueueSize is the number of entries waiting for the sink, 1024 by default
+	QueueSize int
+	// BatchSize is the maximum number of entries handed to a BatchLogSink at once, 64 by default
+	BatchSize int
+	// Policy applies when the queue is full, OverflowBlock by default
+	Policy OverflowPolicy
+}
+
+// BatchLogSink is a LogSink able to write several entries at once, e.g. in a single request.
+// An AsyncSink hands it the entries queued since its last write.
+type BatchLogSink interface {
+	LogSink
+	// WriteLogs writes the entries in order
+	WriteLogs(entries []*LogEntry)
+}
+
+// AsyncSink writes to a LogSink from a background goroutine through a bounded queue, so a slow sink does not stall
+// the callers. It is safe for concurrent use.
+type AsyncSink struct {
+	sink      LogSink
+	batchSize int
+	policy    OverflowPolicy
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	idle     *sync.Cond
+	queue    []*LogEntry // ring buffer
+	head     int
+	count    int
+	busy     bool
+	closed   bool
+
+	dropped   atomic.Uint64
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAsyncSink starts the goroutine writing to sink, config may be nil to use the defaults.
+// Close must be called to write the queued entries and stop the goroutine.
+func NewAsyncSink(sink LogSink, config *AsyncSinkConfig) *AsyncSink {
+	if config == nil {
+		config = new(AsyncSinkConfig)
+	}
+
+	r := &AsyncSink{
+		sink:      sink,
+		batchSize: orDefault(config.BatchSize, DefaultBatchSize),
+		policy:    config.Policy,
+		queue:     make([]*LogEntry, orDefault(config.QueueSize, DefaultQueueSize)),
+		done:      make(chan struct{}),
+	}
+	if r.policy == "" {
+		r.policy = OverflowBlock
+	}
+	r.notEmpty = sync.NewCond(&r.mu)
+	r.notFull = sync.NewCond(&r.mu)
+	r.idle = sync.NewCond(&r.mu)
+
+	go r.run()
+	return r
+}
+
+// WriteLog queues the entry, applying the overflow policy when the queue is full.
+// Entries written after Close are dropped.
+func (o *AsyncSink) WriteLog(entry *LogEntry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.count == len(o.queue) && !o.closed {
+		switch o.policy {
+		case OverflowDropNewest:
+			o.dropped.Add(1)
+			return
+		case OverflowDropOldest:
+			o.queue[o.head] = nil
+			o.head = (o.head + 1) % len(o.queue)
+			o.count--
+			o.dropped.Add(1)
+		default:
+			for o.count == len(o.queue) && !o.closed {
+				o.notFull.Wait()
+			}
+		}
+	}
+	if o.closed {
+		o.dropped.Add(1)
+		return
+	}
+
+	o.queue[(o.head+o.count)%len(o.queue)] = entry
+	o.count++
+	o.notEmpty.Signal()
+}
+
+// Dropped returns the number of entries dropped because the queue was full or the sink closed
+func (o *AsyncSink) Dropped() uint64 {
+	return o.dropped.Load()
+}
+
+// Flush waits until the queued entries are written
+func (o *AsyncSink) Flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for o.count > 0 || o.busy {
+		o.idle.Wait()
+	}
+}
+
+// Close writes the queued entries and stops the goroutine
+func (o *AsyncSink) Close() {
+	o.closeOnce.Do(func() {
+		o.mu.Lock()
+		o.closed = true
+		o.notEmpty.Broadcast()
+		o.notFull.Broadcast()
+		o.mu.Unlock()
+	})
+	<-o.done
+}
+
+// run writes the queued entries until the sink is closed and its queue is empty
+func (o *AsyncSink) run() {
+	defer close(o.done)
+
+	for {
+		batch, ok := o.next()
+		if !ok {
+			return
+		}
+		o.write(batch)
+	}
+}
+
+// next waits for entries and dequeues up to batchSize of them, it returns false once closed and drained
+func (o *AsyncSink) next() ([]*LogEntry, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.busy = false
+	for o.count == 0 {
+		o.idle.Broadcast()
+		if o.closed {
+			return nil, false
+		}
+		o.notEmpty.Wait()
+	}
+
+	n := min(o.count, o.batchSize)
+	batch := make([]*LogEntry, n)
+	for i := range batch {
+		batch[i] = o.queue[o.head]
+		o.queue[o.head] = nil
+		o.head = (o.head + 1) % len(o.queue)
+	}
+	o.count -= n
+	o.busy = true
+	o.notFull.Broadcast()
+	return batch, true
+}
+
+// write hands a batch to the sink, a panicking sink loses the batch but not the goroutine
+func (o *AsyncSink) write(batch []*LogEntry) {
+	defer func() {
+		if err := recover(); err != nil {
+			o.dropped.Add(uint64(len(batch)))
+			fmt.Fprintf(os.Stderr, "xlog: log sink panicked: %v\n", err)
+		}
+	}()
+
+	if s, ok := o.sink.(BatchLogSink); ok {
+		s.WriteLogs(batch)
+		return
+	}
+	for _, entry := range batch {
+		o.sink.WriteLog(entry)
+	}
+}
@@ -0,0 +1,160 @@
+package xlog
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingSink waits for release before writing, recording the batches it receives
+type blockingSink struct {
+	release chan struct{}
+	mu      sync.Mutex
+	batches [][]*LogEntry
+}
+
+func (o *blockingSink) WriteLog(entry *LogEntry) {
+	o.WriteLogs([]*LogEntry{entry})
+}
+
+func (o *blockingSink) WriteLogs(entries []*LogEntry) {
+	<-o.release
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.batches = append(o.batches, entries)
+}
+
+func (o *blockingSink) messages() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var r []string
+	for _, batch := range o.batches {
+		for _, entry := range batch {
+			r = append(r, entry.Message)
+		}
+	}
+	return r
+}
+
+func TestAsyncSink(t *testing.T) {
+	entry := func(msg string) *LogEntry { return &LogEntry{Message: msg} }
+
+	// fill queues a first entry taken by the goroutine, which blocks on it, and fills the queue of 2
+	fill := func(policy OverflowPolicy) (*AsyncSink, *blockingSink) {
+		sink := &blockingSink{release: make(chan struct{})}
+		async := NewAsyncSink(sink, &AsyncSinkConfig{QueueSize: 2, BatchSize: 10, Policy: policy})
+		async.WriteLog(entry("0"))
+		for {
+			async.mu.Lock()
+			busy := async.busy
+			async.mu.Unlock()
+			if busy {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		async.WriteLog(entry("1"))
+		async.WriteLog(entry("2"))
+		return async, sink
+	}
+
+	t.Run("Drop newest", func(t *testing.T) {
+		async, sink := fill(OverflowDropNewest)
+		async.WriteLog(entry("3"))
+		close(sink.release)
+		async.Close()
+
+		if got := sink.messages(); len(got) != 3 || got[2] != "2" || async.Dropped() != 1 {
+			t.Errorf("Expected [0 1 2] and 1 dropped, got %v and %d", got, async.Dropped())
+		}
+	})
+
+	t.Run("Drop oldest", func(t *testing.T) {
+		async, sink := fill(OverflowDropOldest)
+		async.WriteLog(entry("3"))
+		close(sink.release)
+		async.Close()
+
+		if got := sink.messages(); len(got) != 3 || got[1] != "2" || got[2] != "3" || async.Dropped() != 1 {
+			t.Errorf("Expected [0 2 3] and 1 dropped, got %v and %d", got, async.Dropped())
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		async, sink := fill(OverflowBlock)
+		written := make(chan struct{})
+		go func() {
+			async.WriteLog(entry("3"))
+			close(written)
+		}()
+
+		select {
+		case <-written:
+			t.Fatal("Expected the caller to wait for room in the queue")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(sink.release)
+		<-written
+		async.Close()
+
+		if got := sink.messages(); len(got) != 4 || async.Dropped() != 0 {
+			t.Errorf("Expected 4 entries and none dropped, got %v and %d", got, async.Dropped())
+		}
+		// the entries queued while the sink was blocked are written as a batch
+		if len(sink.batches) > 3 {
+			t.Errorf("Expected batched writes, got %d batches", len(sink.batches))
+		}
+	})
+
+	t.Run("Closed sink drops", func(t *testing.T) {
+		sink := &blockingSink{release: make(chan struct{})}
+		close(sink.release)
+		async := NewAsyncSink(sink, nil)
+		async.Close()
+		async.Close()
+		async.WriteLog(entry("late"))
+		if async.Dropped() != 1 {
+			t.Errorf("Expected 1 dropped, got %d", async.Dropped())
+		}
+	})
+}
+
+func TestAsyncLogger(t *testing.T) {
+	sink := new(memorySink)
+	logger := newGologLogger(&LogConfig{Level: "debug", Async: &AsyncSinkConfig{}}, sink).(*GologLogger)
+
+	for i := 0; i < 100; i++ {
+		logger.Infow("message", "i", i)
+	}
+	logger.asyncSinks[0].Flush()
+	if len(sink.entries) != 100 {
+		t.Errorf("Expected 100 entries after Flush, got %d", len(sink.entries))
+	}
+
+	logger.Info("last")
+	logger.Finalize()
+	if len(sink.entries) != 101 || sink.entries[100].Message != "last" || logger.DroppedEntries() != 0 {
+		t.Errorf("Expected every entry to be written in order by Finalize, got %d", len(sink.entries))
+	}
+}
+
+func TestInitFinalizesThePreviousLogger(t *testing.T) {
+	defer SetLogger(GetLogger())
+
+	sink := &blockingSink{release: make(chan struct{})}
+	close(sink.release)
+	Init(&LogConfig{Level: "debug", Async: &AsyncSinkConfig{}}, sink)
+	previous := GetLogger().(*GologLogger)
+	for i := 0; i < 10; i++ {
+		Infow("message", "i", i)
+	}
+
+	Init(&LogConfig{Level: "debug"})
+	if n := len(sink.messages()); n != 10 {
+		t.Errorf("Expected the queued entries written by the second Init, got %d", n)
+	}
+	previous.Info("after Finalize")
+	if n := len(sink.messages()); n != 10 {
+		t.Errorf("Expected the async sink closed, got %d entries", n)
+	}
+}
@@ -0,0 +1,158 @@
+package xlog
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// stackTracer is implemented by the errors of xerr and github.com/pkg/errors, which record where they were created
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// _maxStackDepth limits the number of frames of a captured stack
+const _maxStackDepth = 32
+
+// _wrapperPrefixes are the function name prefixes of the logging wrappers skipped to find the caller:
+// this package, log/slog forwarding to SlogHandler and the handlers of xerr which log on behalf of their caller
+var _wrapperPrefixes = func() []string {
+	pc, _, _, _ := runtime.Caller(0)
+	pkg := packagePath(runtime.FuncForPC(pc).Name())
+	module := strings.TrimSuffix(pkg, "/xlog")
+	return []string{
+		pkg + ".",
+		"log/slog.",
+		module + "/xerr.LogError",
+		module + "/xerr.FatalIfErr",
+	}
+}()
+
+// packagePath returns the import path of the package of a function name, e.g. "github.com/a/b" for "github.com/a/b.(*T).M"
+func packagePath(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[slash+1:], '.'); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// isWrapper reports whether a frame belongs to a logging wrapper, tests of this package are callers
+func isWrapper(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	for _, prefix := range _wrapperPrefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+	return strings.HasPrefix(frame.Function, "runtime.")
+}
+
+// callerFrames returns the frames from the first caller outside the logging wrappers, at most depth of them
+func callerFrames(depth int) []runtime.Frame {
+	pcs := make([]uintptr, depth+16)
+	n := runtime.Callers(3, pcs) // skip runtime.Callers, callerFrames and its caller
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var r []runtime.Frame
+	found := false
+	for {
+		frame, more := frames.Next()
+		if found && strings.HasPrefix(frame.Function, "runtime.") {
+			break
+		}
+		if found || !isWrapper(frame) {
+			found = true
+			r = append(r, frame)
+			if len(r) == depth {
+				break
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return r
+}
+
+// callerPC returns the program counter of the first caller outside the logging wrappers, as slog.Record.PC expects
+func callerPC() uintptr {
+	var pcs [_maxStackDepth]uintptr
+	n := runtime.Callers(3, pcs[:]) // skip runtime.Callers, callerPC and its caller
+	for _, pc := range pcs[:n] {
+		// a program counter holds several frames when functions are inlined
+		frames := runtime.CallersFrames([]uintptr{pc})
+		for {
+			frame, more := frames.Next()
+			if !isWrapper(frame) {
+				return pc
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return 0
+}
+
+// formatCaller formats a frame as "dir/file.go:line:package.Function"
+func formatCaller(frame runtime.Frame) string {
+	dir, file := filepath.Split(frame.File)
+	file = filepath.Base(dir) + "/" + file
+
+	function := frame.Function
+	if slash := strings.LastIndexByte(function, '/'); slash >= 0 {
+		function = function[slash+1:]
+	}
+	return file + ":" + strconv.Itoa(frame.Line) + ":" + function
+}
+
+// formatStack formats frames the way github.com/pkg/errors prints a stack with %+v
+func formatStack(frames []runtime.Frame) string {
+	var sb strings.Builder
+	for _, frame := range frames {
+		sb.WriteString(frame.Function)
+		sb.WriteString("\n\t")
+		sb.WriteString(frame.File)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(frame.Line))
+		sb.WriteByte('\n')
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// errorStack returns the stack recorded by the innermost error of v carrying one
+func errorStack(v interface{}) (string, bool) {
+	err, ok := v.(error)
+	if !ok {
+		return "", false
+	}
+
+	var st stackTracer
+	for ; err != nil; err = errors.Unwrap(err) {
+		if s, ok := err.(stackTracer); ok {
+			st = s
+		}
+	}
+	if st == nil {
+		return "", false
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%+v", st.StackTrace()), "\n"), true
+}
+
+// firstErrorStack returns the stack recorded by the first error of values carrying one
+func firstErrorStack(values []interface{}) (string, bool) {
+	for _, v := range values {
+		if stack, ok := errorStack(v); ok {
+			return stack, true
+		}
+	}
+	return "", false
+}
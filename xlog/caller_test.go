@@ -0,0 +1,82 @@
+package xlog
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func newStackError() error {
+	return errors.New("boom")
+}
+
+func TestCaller(t *testing.T) {
+	logger, buf, sink := newTestLogger(&LogConfig{Level: "debug", TraceLevel: LogLevelError})
+
+	// line returns the caller of the entry logged on the next line
+	line := func() string {
+		_, _, n, _ := runtime.Caller(1)
+		return "xlog/caller_test.go:" + strconv.Itoa(n+1) + ":xlog.TestCaller"
+	}
+
+	expected := []string{line()}
+	logger.Info("direct")
+	expected = append(expected, line())
+	logger.With("k", "v").Infow("child")
+
+	// through the package-level wrappers
+	defer SetLogger(GetLogger())
+	SetLogger(logger)
+	expected = append(expected, line())
+	Warnf("wrapped %d", 1)
+
+	for i, e := range expected {
+		if sink.entries[i].Caller != e {
+			t.Errorf("Expected caller '%s', got '%s'", e, sink.entries[i].Caller)
+		}
+		if sink.entries[i].Stack != "" {
+			t.Errorf("Expected no stack below TraceLevel, got %q", sink.entries[i].Stack)
+		}
+	}
+
+	t.Run("Stack of the caller", func(t *testing.T) {
+		Error("failed")
+		entry := sink.entries[len(sink.entries)-1]
+		if !strings.HasPrefix(entry.Stack, "github.com/DreamvatLab/go/xlog.TestCaller") || strings.Contains(entry.Stack, "runtime.goexit") {
+			t.Errorf("Unexpected stack %q", entry.Stack)
+		}
+	})
+
+	t.Run("Stack of the error", func(t *testing.T) {
+		buf.Reset()
+		logger.Error("request failed: ", errors.Wrap(newStackError(), "handler"))
+		entry := sink.entries[len(sink.entries)-1]
+		if entry.Message != "request failed: handler: boom" {
+			t.Errorf("Expected the error text only, got %q", entry.Message)
+		}
+		if !strings.HasPrefix(entry.Stack, "github.com/DreamvatLab/go/xlog.newStackError") {
+			t.Errorf("Expected the stack of the innermost error, got %q", entry.Stack)
+		}
+		if n := strings.Count(buf.String(), "xlog.newStackError"); n != 1 {
+			t.Errorf("Expected the stack to be printed once, got %d times in %q", n, buf.String())
+		}
+
+		buf.Reset()
+		logger.Errorf("reload failed: %+v", errors.Wrap(newStackError(), "reload"))
+		entry = sink.entries[len(sink.entries)-1]
+		if entry.Stack != "" || !strings.Contains(entry.Message, "xlog.newStackError") {
+			t.Errorf("Expected the stack in the message only, got %q and %q", entry.Message, entry.Stack)
+		}
+		if n := strings.Count(buf.String(), "xlog.newStackError"); n != 1 {
+			t.Errorf("Expected the stack to be printed once, got %d times in %q", n, buf.String())
+		}
+
+		logger.Errorw("request failed", "error", newStackError())
+		if entry := sink.entries[len(sink.entries)-1]; !strings.HasPrefix(entry.Stack, "github.com/DreamvatLab/go/xlog.newStackError") {
+			t.Errorf("Expected the stack of the error field, got %q", entry.Stack)
+		}
+	})
+}
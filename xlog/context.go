@@ -0,0 +1,188 @@
+package xlog
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Keys of the correlation IDs in the output
+const (
+	RequestIDKey = "request_id"
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+)
+
+type contextKey int
+
+const (
+	_loggerContextKey contextKey = iota
+	_fieldsContextKey
+	_requestIDContextKey
+	_traceContextKey
+)
+
+// TraceContext identifies the trace and span of a request, as carried by the W3C traceparent header
+type TraceContext struct {
+	// TraceID is the 32 hex digits trace ID
+	TraceID string
+	// SpanID is the 16 hex digits ID of the parent span
+	SpanID string
+	// Sampled reports whether the caller records the trace
+	Sampled bool
+}
+
+// ParseTraceparent parses a W3C traceparent header, e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func ParseTraceparent(header string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q", header)
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	// future versions may append fields, version 00 has exactly four
+	if !isHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return TraceContext{}, fmt.Errorf("invalid traceparent version in %q", header)
+	}
+	if !isHex(traceID, 32) || traceID == strings.Repeat("0", 32) {
+		return TraceContext{}, fmt.Errorf("invalid trace ID in traceparent %q", header)
+	}
+	if !isHex(spanID, 16) || spanID == strings.Repeat("0", 16) {
+		return TraceContext{}, fmt.Errorf("invalid span ID in traceparent %q", header)
+	}
+	if !isHex(flags, 2) {
+		return TraceContext{}, fmt.Errorf("invalid trace flags in traceparent %q", header)
+	}
+
+	flagBits, _ := hex.DecodeString(flags)
+	return TraceContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: flagBits[0]&1 == 1,
+	}, nil
+}
+
+// Traceparent formats the trace context as a version 00 traceparent header
+func (o TraceContext) Traceparent() string {
+	flags := "00"
+	if o.Sampled {
+		flags = "01"
+	}
+	return "00-" + o.TraceID + "-" + o.SpanID + "-" + flags
+}
+
+// isHex reports whether s is made of n lowercase hex digits
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the logger, returned by FromContext and Ctx
+func NewContext(ctx context.Context, logger ILogger) context.Context {
+	return context.WithValue(ctx, _loggerContextKey, logger)
+}
+
+// FromContext returns the logger stored in ctx by NewContext, or the global logger
+func FromContext(ctx context.Context) ILogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(_loggerContextKey).(ILogger); ok {
+			return logger
+		}
+	}
+	return GetLogger()
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID written to the entries logged with it
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, _requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	r, _ := ctx.Value(_requestIDContextKey).(string)
+	return r
+}
+
+// ContextWithTrace returns a copy of ctx carrying the trace context written to the entries logged with it
+func ContextWithTrace(ctx context.Context, trace TraceContext) context.Context {
+	return context.WithValue(ctx, _traceContextKey, trace)
+}
+
+// ContextWithTraceparent parses a traceparent header and stores it in a copy of ctx.
+// ctx is returned unchanged with the error when the header is invalid.
+func ContextWithTraceparent(ctx context.Context, header string) (context.Context, error) {
+	trace, err := ParseTraceparent(header)
+	if err != nil {
+		return ctx, err
+	}
+	return ContextWithTrace(ctx, trace), nil
+}
+
+// TraceFromContext returns the trace context stored in ctx
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	if ctx == nil {
+		return TraceContext{}, false
+	}
+	r, ok := ctx.Value(_traceContextKey).(TraceContext)
+	return r, ok
+}
+
+// ContextWithFields returns a copy of ctx carrying the alternating keys and values, added to the entries logged with it.
+// The fields are merged with those already stored in ctx.
+func ContextWithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	return context.WithValue(ctx, _fieldsContextKey, MergeFields(FieldsFromContext(ctx), ToFields(keysAndValues)))
+}
+
+// FieldsFromContext returns the fields stored in ctx by ContextWithFields
+func FieldsFromContext(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(_fieldsContextKey).(map[string]interface{})
+	return r
+}
+
+// Ctx returns the logger of ctx with the request ID, trace context and fields of ctx
+func Ctx(ctx context.Context) ILogger {
+	logger := FromContext(ctx)
+	if logger == nil {
+		panic("logger is not initialized")
+	}
+	return logger.WithContext(ctx)
+}
+
+// DebugCtx logs a message at debug level with the logger and correlation IDs of ctx
+func DebugCtx(ctx context.Context, v ...interface{}) {
+	Ctx(ctx).Debug(v...)
+}
+
+// InfoCtx logs a message at info level with the logger and correlation IDs of ctx
+func InfoCtx(ctx context.Context, v ...interface{}) {
+	Ctx(ctx).Info(v...)
+}
+
+// WarnCtx logs a message at warning level with the logger and correlation IDs of ctx
+func WarnCtx(ctx context.Context, v ...interface{}) {
+	Ctx(ctx).Warn(v...)
+}
+
+// ErrorCtx logs a message at error level with the logger and correlation IDs of ctx
+func ErrorCtx(ctx context.Context, v ...interface{}) {
+	Ctx(ctx).Error(v...)
+}
+
+// FatalCtx logs a message at fatal level with the logger and correlation IDs of ctx and then calls Exit(1)
+func FatalCtx(ctx context.Context, v ...interface{}) {
+	Ctx(ctx).Fatal(v...)
+}
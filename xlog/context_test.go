@@ -0,0 +1,86 @@
+package xlog
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	trace, err := ParseTraceparent(header)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if trace.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || trace.SpanID != "00f067aa0ba902b7" || !trace.Sampled {
+		t.Errorf("Unexpected trace context %+v", trace)
+	}
+	if s := trace.Traceparent(); s != header {
+		t.Errorf("Expected '%s', got '%s'", header, s)
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	}
+	for _, s := range invalid {
+		if _, err := ParseTraceparent(s); err == nil {
+			t.Errorf("Expected error for '%s'", s)
+		}
+	}
+
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil {
+		t.Errorf("Expected future versions to be accepted, got %v", err)
+	}
+}
+
+func TestContextLogging(t *testing.T) {
+	logger, buf, sink := newTestLogger(&LogConfig{Level: "debug", Format: LogFormatLogfmt})
+
+	ctx := NewContext(context.Background(), logger.With("service", "api"))
+	ctx = ContextWithRequestID(ctx, "r-1")
+	ctx, err := ContextWithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ctx = ContextWithFields(ctx, "tenant", "acme")
+	ctx = ContextWithFields(ctx, "user_id", 7)
+
+	InfoCtx(ctx, "handled")
+	Ctx(ctx).Warnw("slow", "ms", 1200)
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(sink.entries))
+	}
+	entry := sink.entries[0]
+	if entry.RequestID != "r-1" || entry.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || entry.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("Unexpected correlation IDs %+v", entry)
+	}
+	if entry.Fields["service"] != "api" || entry.Fields["tenant"] != "acme" || entry.Fields["user_id"] != 7 {
+		t.Errorf("Unexpected fields %v", entry.Fields)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := "request_id=r-1 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 ms=1200 service=api tenant=acme user_id=7"
+	if len(lines) != 2 || !strings.Contains(lines[1], "msg=slow") || !strings.HasSuffix(lines[1], expected) {
+		t.Errorf("Expected line ending with %q, got %q", expected, lines)
+	}
+
+	t.Run("Without values", func(t *testing.T) {
+		if FromContext(context.Background()) != GetLogger() {
+			t.Error("Expected the global logger")
+		}
+		if _, err := ContextWithTraceparent(context.Background(), "invalid"); err == nil {
+			t.Error("Expected error for invalid traceparent")
+		}
+		if RequestIDFromContext(context.Background()) != "" || FieldsFromContext(context.Background()) != nil {
+			t.Error("Expected no correlation values")
+		}
+	})
+}
@@ -0,0 +1,426 @@
+package xlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kataras/golog"
+	"github.com/kataras/golog/printer"
+)
+
+// 输出格式
+const (
+	LogFormatText   = "text"
+	LogFormatJSON   = "json"
+	LogFormatLogfmt = "logfmt"
+)
+
+// Keys of the entry properties in the JSON and logfmt output
+const (
+	TimeKey    = "time"
+	LevelKey   = "level"
+	LoggerKey  = "logger"
+	MessageKey = "msg"
+	CallerKey  = "caller"
+	StackKey   = "stack"
+)
+
+// FieldKeyPrefix prefixes the fields whose key is the key of an entry property written in the JSON and logfmt
+// output, e.g. a "level" field is written as "fields.level"
+const FieldKeyPrefix = "fields."
+
+// LogEncoder serializes log entries for the console and file outputs
+type LogEncoder interface {
+	// Encode writes the entry to w as a single line ending with a newline
+	Encode(w io.Writer, entry *LogEntry) error
+}
+
+// EncoderConfig customizes the built-in encoders
+type EncoderConfig struct {
+	// TimeFormat is the layout of the entry time, "2006/01/02 15:04:05" for text and RFC 3339 with milliseconds otherwise
+	TimeFormat string
+	// UTC writes the time in UTC instead of the local time zone
+	UTC bool
+	// LevelNames overrides the name written for a level, e.g. {"warn": "WARNING"}
+	LevelNames map[string]string
+	// FieldOrder lists the keys written first, in order, e.g. ["time", "level", "request_id", "msg"].
+	// The other keys follow in the default order: time, level, logger, msg, caller, request_id, trace_id, span_id,
+	// the fields sorted by key, stack.
+	FieldOrder []string
+}
+
+// NewEncoder creates the built-in encoder of a format: "text" (or empty), "json" or "logfmt"
+func NewEncoder(format string, config *EncoderConfig) (LogEncoder, error) {
+	if config == nil {
+		config = new(EncoderConfig)
+	}
+
+	switch strings.ToLower(format) {
+	case "", LogFormatText:
+		return &textEncoder{newEncoderOptions(config, "2006/01/02 15:04:05")}, nil
+	case LogFormatJSON:
+		return &jsonEncoder{newEncoderOptions(config, "2006-01-02T15:04:05.000Z07:00")}, nil
+	case LogFormatLogfmt:
+		return &logfmtEncoder{newEncoderOptions(config, "2006-01-02T15:04:05.000Z07:00")}, nil
+	}
+	return nil, fmt.Errorf("unknown log format %q, expected text, json or logfmt", format)
+}
+
+// EncodeEntry serializes an entry with the encoder of the global logger, so sinks can write the same lines as the console
+func EncodeEntry(entry *LogEntry) ([]byte, error) {
+	encoder := _defaultEncoder
+	if l, ok := GetLogger().(*GologLogger); ok {
+		encoder = l.encoder
+	}
+
+	var buf bytes.Buffer
+	err := encoder.Encode(&buf, entry)
+	return buf.Bytes(), err
+}
+
+var _defaultEncoder, _ = NewEncoder(LogFormatText, nil)
+
+// encoderOptions holds the resolved EncoderConfig shared by the built-in encoders
+type encoderOptions struct {
+	timeFormat string
+	utc        bool
+	levelNames map[string]string
+	order      map[string]int
+}
+
+func newEncoderOptions(config *EncoderConfig, defaultTimeFormat string) encoderOptions {
+	r := encoderOptions{
+		timeFormat: config.TimeFormat,
+		utc:        config.UTC,
+		levelNames: config.LevelNames,
+		order:      make(map[string]int, len(config.FieldOrder)),
+	}
+	if r.timeFormat == "" {
+		r.timeFormat = defaultTimeFormat
+	}
+	for i, key := range config.FieldOrder {
+		r.order[key] = i
+	}
+	return r
+}
+
+// formatTime formats the entry time
+func (o *encoderOptions) formatTime(t time.Time) string {
+	if o.utc {
+		t = t.UTC()
+	}
+	return t.Format(o.timeFormat)
+}
+
+// levelName returns the configured name of a level, or defaultName
+func (o *encoderOptions) levelName(level int, defaultName string) string {
+	if name, ok := o.levelNames[LevelName(level)]; ok {
+		return name
+	}
+	return defaultName
+}
+
+// keyValue is a single property of an encoded entry
+type keyValue struct {
+	key   string
+	value interface{}
+}
+
+// pairs returns the properties of an entry in output order
+func (o *encoderOptions) pairs(entry *LogEntry) []keyValue {
+	r := make([]keyValue, 0, 6+len(entry.Fields))
+	r = append(r,
+		keyValue{TimeKey, o.formatTime(entry.Time)},
+		keyValue{LevelKey, o.levelName(entry.Level, LevelName(entry.Level))},
+	)
+	if entry.LoggerName != "" {
+		r = append(r, keyValue{LoggerKey, entry.LoggerName})
+	}
+	r = append(r, keyValue{MessageKey, entry.Message})
+	if entry.Caller != "" {
+		r = append(r, keyValue{CallerKey, entry.Caller})
+	}
+	r = append(r, correlationIDs(entry)...)
+	properties := r
+	if entry.Stack != "" {
+		properties = append(properties[:len(properties):len(properties)], keyValue{StackKey, entry.Stack})
+	}
+	for _, kv := range o.fields(entry.Fields) {
+		kv.key = fieldKey(kv.key, properties, entry.Fields)
+		r = append(r, kv)
+	}
+	if entry.Stack != "" {
+		r = append(r, keyValue{StackKey, entry.Stack})
+	}
+
+	if len(o.order) > 0 {
+		sort.SliceStable(r, func(i, j int) bool {
+			return o.rank(r[i].key) < o.rank(r[j].key)
+		})
+	}
+	return r
+}
+
+// correlationIDs returns the request, trace and span IDs of an entry which are set
+func correlationIDs(entry *LogEntry) []keyValue {
+	var r []keyValue
+	if entry.RequestID != "" {
+		r = append(r, keyValue{RequestIDKey, entry.RequestID})
+	}
+	if entry.TraceID != "" {
+		r = append(r, keyValue{TraceIDKey, entry.TraceID})
+	}
+	if entry.SpanID != "" {
+		r = append(r, keyValue{SpanIDKey, entry.SpanID})
+	}
+	return r
+}
+
+// fields returns the structured fields sorted by key
+func (o *encoderOptions) fields(fields map[string]interface{}) []keyValue {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	r := make([]keyValue, 0, len(keys))
+	for _, key := range keys {
+		r = append(r, keyValue{key, fields[key]})
+	}
+	return r
+}
+
+// fieldKey returns the key of a field in the JSON and logfmt output, prefixed with FieldKeyPrefix
+// until it differs from the keys of the entry properties and of the other fields
+func fieldKey(key string, properties []keyValue, fields map[string]interface{}) string {
+	collides := func(key string) bool {
+		for _, p := range properties {
+			if p.key == key {
+				return true
+			}
+		}
+		return false
+	}
+	if !collides(key) {
+		return key
+	}
+	for {
+		key = FieldKeyPrefix + key
+		if _, ok := fields[key]; !ok && !collides(key) {
+			return key
+		}
+	}
+}
+
+// rank returns the position of a key listed in FieldOrder, the other keys come after
+func (o *encoderOptions) rank(key string) int {
+	if i, ok := o.order[key]; ok {
+		return i
+	}
+	return len(o.order)
+}
+
+// textEncoder writes golog's human readable lines: [INFO] 2006/01/02 15:04:05 message key=value
+type textEncoder struct {
+	encoderOptions
+}
+
+func (o *textEncoder) Encode(w io.Writer, entry *LogEntry) error {
+	var buf bytes.Buffer
+	buf.WriteByte(' ')
+	buf.WriteString(o.formatTime(entry.Time))
+	buf.WriteByte(' ')
+	if entry.LoggerName != "" {
+		buf.WriteString("[" + entry.LoggerName + "] ")
+	}
+	if entry.Caller != "" {
+		buf.WriteString(entry.Caller + " ")
+	}
+	buf.WriteString(entry.Message)
+	for _, kv := range append(correlationIDs(entry), o.orderedFields(entry.Fields)...) {
+		buf.WriteByte(' ')
+		buf.WriteString(kv.key)
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(kv.value))
+	}
+	if entry.Stack != "" {
+		buf.WriteByte('\n')
+		buf.WriteString(strings.TrimRight(entry.Stack, "\n"))
+	}
+	buf.WriteByte('\n')
+
+	// the level title is colored on terminals only
+	title, color := o.levelTitle(entry.Level)
+	if _, err := printer.WriteRich(w, title, color); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// orderedFields returns the structured fields, those listed in FieldOrder first
+func (o *textEncoder) orderedFields(fields map[string]interface{}) []keyValue {
+	r := o.fields(fields)
+	if len(o.order) > 0 {
+		sort.SliceStable(r, func(i, j int) bool {
+			return o.rank(r[i].key) < o.rank(r[j].key)
+		})
+	}
+	return r
+}
+
+// levelTitle returns the title and color of a level as golog prints them
+func (o *textEncoder) levelTitle(level int) (string, int) {
+	meta, ok := golog.Levels[toGologLevel(level)]
+	if !ok {
+		return o.levelName(level, LevelName(level)), 0
+	}
+	return o.levelName(level, meta.Title), meta.ColorCode
+}
+
+// jsonEncoder writes one JSON object per line
+type jsonEncoder struct {
+	encoderOptions
+}
+
+func (o *jsonEncoder) Encode(w io.Writer, entry *LogEntry) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, kv := range o.pairs(entry) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(kv.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(jsonValue(kv.value))
+	}
+	buf.WriteString("}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// jsonValue marshals a field value, errors are written as their message and unsupported values with fmt
+func jsonValue(v interface{}) []byte {
+	switch val := v.(type) {
+	case error:
+		if _, ok := v.(json.Marshaler); !ok {
+			v = val.Error()
+		}
+	case fmt.Stringer:
+		if _, ok := v.(json.Marshaler); !ok {
+			v = val.String()
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return data
+}
+
+// logfmtEncoder writes key=value pairs separated by spaces
+type logfmtEncoder struct {
+	encoderOptions
+}
+
+func (o *logfmtEncoder) Encode(w io.Writer, entry *LogEntry) error {
+	var buf bytes.Buffer
+	for i, kv := range o.pairs(entry) {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(logfmtKey(kv.key))
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(kv.value))
+	}
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// logfmtKey removes the characters a logfmt key cannot hold
+func logfmtKey(key string) string {
+	if key == "" {
+		return BadKey
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue formats a value, quoting it when it is empty or holds spaces, quotes, '=' or control characters
+func logfmtValue(v interface{}) string {
+	var s string
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		s = val
+	case error:
+		s = val.Error()
+	case fmt.Stringer:
+		s = val.String()
+	case []byte:
+		s = string(val)
+	default:
+		s = fmt.Sprint(val)
+	}
+
+	if s == "" || !utf8.ValidString(s) || strings.IndexFunc(s, needsQuote) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError
+}
+
+// _entryField carries the LogEntry through golog to the formatter
+const _entryField = "\x00xlog.entry"
+
+// gologFormatter writes golog logs with a LogEncoder
+type gologFormatter struct {
+	encoder LogEncoder
+}
+
+func (o *gologFormatter) String() string {
+	return "xlog"
+}
+
+func (o *gologFormatter) Options(opts ...interface{}) golog.Formatter {
+	return o
+}
+
+func (o *gologFormatter) Format(dest io.Writer, log *golog.Log) bool {
+	entry, ok := log.Fields[_entryField].(*LogEntry)
+	if !ok {
+		// written through the golog logger directly
+		entry = &LogEntry{
+			Level:   convertGologLevel(log.Level),
+			Time:    log.Time,
+			Message: log.Message,
+			Fields:  map[string]interface{}(log.Fields),
+		}
+	}
+
+	if err := o.encoder.Encode(dest, entry); err != nil {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package xlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncoders(t *testing.T) {
+	entry := &LogEntry{
+		Level:   3,
+		Time:    time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("CST", 8*3600)),
+		Message: "disk almost full",
+		Fields: map[string]interface{}{
+			"path":  "/var/lib data",
+			"used":  0.93,
+			"error": errors.New("quota"),
+		},
+	}
+
+	encode := func(format string, config *EncoderConfig) string {
+		encoder, err := NewEncoder(format, config)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var buf bytes.Buffer
+		if err := encoder.Encode(&buf, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return buf.String()
+	}
+
+	t.Run("Text", func(t *testing.T) {
+		expected := `[WARN] 2024/05/06 07:08:09 disk almost full error=quota path="/var/lib data" used=0.93` + "\n"
+		if s := encode(LogFormatText, nil); s != expected {
+			t.Errorf("Expected %q, got %q", expected, s)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		s := encode(LogFormatJSON, nil)
+		expected := `{"time":"2024-05-06T07:08:09.000+08:00","level":"warn","msg":"disk almost full","error":"quota","path":"/var/lib data","used":0.93}` + "\n"
+		if s != expected {
+			t.Errorf("Expected %q, got %q", expected, s)
+		}
+		if !json.Valid([]byte(s)) {
+			t.Errorf("Expected valid JSON, got %s", s)
+		}
+	})
+
+	t.Run("Logfmt", func(t *testing.T) {
+		expected := `time=2024-05-06T07:08:09.000+08:00 level=warn msg="disk almost full" error=quota path="/var/lib data" used=0.93` + "\n"
+		if s := encode(LogFormatLogfmt, nil); s != expected {
+			t.Errorf("Expected %q, got %q", expected, s)
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		config := &EncoderConfig{
+			TimeFormat: time.RFC3339,
+			UTC:        true,
+			LevelNames: map[string]string{LogLevelWarn: "WARNING"},
+			FieldOrder: []string{LevelKey, "used", MessageKey},
+		}
+		expected := `level=WARNING used=0.93 msg="disk almost full" time=2024-05-05T23:08:09Z error=quota path="/var/lib data"` + "\n"
+		if s := encode(LogFormatLogfmt, config); s != expected {
+			t.Errorf("Expected %q, got %q", expected, s)
+		}
+	})
+
+	t.Run("Colliding keys", func(t *testing.T) {
+		entry := &LogEntry{
+			Level:   3,
+			Time:    entry.Time,
+			Message: "level changed",
+			Fields:  map[string]interface{}{"level": "debug", "fields.level": "x", "msg": "m"},
+		}
+		encoder, _ := NewEncoder(LogFormatJSON, nil)
+		var buf bytes.Buffer
+		if err := encoder.Encode(&buf, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := `{"time":"2024-05-06T07:08:09.000+08:00","level":"warn","msg":"level changed","fields.level":"x","fields.fields.level":"debug","fields.msg":"m"}` + "\n"
+		if buf.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		if _, err := NewEncoder("xml", nil); err == nil {
+			t.Error("Expected error for unknown format")
+		}
+	})
+}
+
+func TestLogFormat(t *testing.T) {
+	logger, buf, sink := newTestLogger(&LogConfig{Level: "debug", Format: LogFormatJSON})
+
+	logger.With("request_id", "r-1").Infow("served", "status", 200)
+
+	line := strings.TrimSpace(buf.String())
+	var out map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &out); err != nil {
+		t.Fatalf("Expected a JSON line, got %q", line)
+	}
+	if out["msg"] != "served" || out["level"] != "info" || out["request_id"] != "r-1" || out["status"] != float64(200) {
+		t.Errorf("Unexpected output %v", out)
+	}
+
+	t.Run("EncodeEntry uses the global encoder", func(t *testing.T) {
+		defer SetLogger(GetLogger())
+		SetLogger(logger)
+
+		data, err := EncodeEntry(sink.entries[0])
+		if err != nil || strings.TrimSpace(string(data)) != line {
+			t.Errorf("Expected %q, got %q (%v)", line, data, err)
+		}
+	})
+
+	t.Run("Unknown format falls back to text", func(t *testing.T) {
+		logger, buf, _ := newTestLogger(&LogConfig{Format: "xml"})
+		logger.Info("hello")
+		if !strings.HasPrefix(buf.String(), "[INFO] ") {
+			t.Errorf("Expected text output, got %q", buf.String())
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package xlog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds the shutdown hooks and the flushing of the logger before a fatal exit
+const DefaultShutdownTimeout = 5 * time.Second
+
+var (
+	_exitMu          sync.Mutex
+	_exitFunc        = os.Exit
+	_shutdownHooks   []func(ctx context.Context)
+	_shutdownTimeout = DefaultShutdownTimeout
+
+	// _shutdowns tracks the shutdowns still running after their deadline
+	_shutdowns sync.WaitGroup
+)
+
+// flusher is implemented by the loggers writing entries in the background
+type flusher interface {
+	Flush()
+}
+
+// RegisterShutdownHook registers a function run by Exit, e.g. after a fatal entry, before the process ends.
+// The hooks run once, in the reverse order of their registration like deferred calls, and should return when ctx
+// is done at the shutdown deadline.
+func RegisterShutdownHook(hook func(ctx context.Context)) {
+	_exitMu.Lock()
+	defer _exitMu.Unlock()
+	_shutdownHooks = append(_shutdownHooks, hook)
+}
+
+// SetExitFunc replaces the function ending the process in Exit, os.Exit by default, and returns the previous one.
+// Tests and libraries can observe fatal entries with a function that returns, the caller of Fatal then goes on.
+func SetExitFunc(exit func(code int)) func(code int) {
+	_exitMu.Lock()
+	defer _exitMu.Unlock()
+
+	r := _exitFunc
+	_exitFunc = exit
+	return r
+}
+
+// SetShutdownTimeout changes the time given to the shutdown hooks and the flushing of the logger in Exit,
+// DefaultShutdownTimeout if timeout is not positive
+func SetShutdownTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	_exitMu.Lock()
+	defer _exitMu.Unlock()
+	_shutdownTimeout = timeout
+}
+
+// Flush writes the entries the global logger queued for its sinks and the pending summaries of the sampling
+func Flush() {
+	if l, ok := GetLogger().(flusher); ok {
+		l.Flush()
+	}
+}
+
+// Exit runs the shutdown hooks and flushes the global logger within the shutdown timeout, then ends the process
+// with the exit function. Fatal, Fatalf and Fatalw call Exit(1).
+func Exit(code int) {
+	_exitMu.Lock()
+	hooks := _shutdownHooks
+	_shutdownHooks = nil
+	timeout := _shutdownTimeout
+	exit := _exitFunc
+	_exitMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	done := make(chan struct{})
+	_shutdowns.Add(1)
+	go func() {
+		defer _shutdowns.Done()
+		defer close(done)
+		for i := len(hooks) - 1; i >= 0; i-- {
+			runShutdownHook(ctx, hooks[i])
+		}
+		Flush()
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		fmt.Fprintf(os.Stderr, "xlog: the shutdown did not complete within %s\n", timeout)
+	}
+	cancel()
+	exit(code)
+}
+
+// runShutdownHook runs a hook, a panic does not prevent the other hooks from running
+func runShutdownHook(ctx context.Context, hook func(ctx context.Context)) {
+	defer func() {
+		if err := recover(); err != nil {
+			fmt.Fprintf(os.Stderr, "xlog: shutdown hook panicked: %v\n", err)
+		}
+	}()
+	hook(ctx)
+}
@@ -0,0 +1,119 @@
+package xlog
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowSink takes time to write an entry
+type slowSink struct {
+	memorySink
+}
+
+func (o *slowSink) WriteLog(entry *LogEntry) {
+	time.Sleep(10 * time.Millisecond)
+	o.memorySink.WriteLog(entry)
+}
+
+// exitRecorder records the exit codes and the hooks run, from the goroutine of Exit
+type exitRecorder struct {
+	mu    sync.Mutex
+	codes []int
+	order []string
+}
+
+func (o *exitRecorder) exit(code int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.codes = append(o.codes, code)
+}
+
+func (o *exitRecorder) hook(name string) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.order = append(o.order, name)
+	}
+}
+
+func (o *exitRecorder) get() ([]int, []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]int(nil), o.codes...), append([]string(nil), o.order...)
+}
+
+func TestExit(t *testing.T) {
+	recorder := new(exitRecorder)
+	defer SetExitFunc(SetExitFunc(recorder.exit))
+	defer SetLogger(GetLogger())
+
+	sink := new(slowSink)
+	logger := newGologLogger(&LogConfig{Level: LogLevelFatal, Async: &AsyncSinkConfig{}}, sink).(*GologLogger)
+	buf := new(strings.Builder)
+	logger.innerLogger.SetOutput(buf)
+	SetLogger(logger)
+	defer logger.Finalize()
+
+	RegisterShutdownHook(recorder.hook("first"))
+	RegisterShutdownHook(func(ctx context.Context) { panic("broken hook") })
+	RegisterShutdownHook(func(ctx context.Context) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected a deadline")
+		}
+		recorder.hook("last")(ctx)
+	})
+
+	Error("filtered")
+	Fatalf("cannot start: %s", "port in use")
+
+	codes, order := recorder.get()
+	if len(codes) != 1 || codes[0] != 1 {
+		t.Fatalf("Expected exit code 1, got %v", codes)
+	}
+	if strings.Join(order, ",") != "last,first" {
+		t.Errorf("Expected the hooks in reverse order, got %v", order)
+	}
+	sink.mu.Lock()
+	if len(sink.entries) != 1 || sink.entries[0].Message != "cannot start: port in use" {
+		t.Errorf("Expected the fatal entry flushed to the sink, got %v", sink.entries)
+	}
+	sink.mu.Unlock()
+	if !strings.HasPrefix(buf.String(), "[FTAL] ") {
+		t.Errorf("Expected the fatal entry printed, got %q", buf.String())
+	}
+
+	t.Run("Hooks run once", func(t *testing.T) {
+		logger.Fatal("again")
+		if codes, order := recorder.get(); len(order) != 2 || len(codes) != 2 {
+			t.Errorf("Expected no more hooks and a second exit, got %v and %v", order, codes)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		defer SetShutdownTimeout(0)
+		SetShutdownTimeout(20 * time.Millisecond)
+
+		release := make(chan struct{})
+		RegisterShutdownHook(func(ctx context.Context) {
+			<-ctx.Done()
+			<-release
+		})
+		start := time.Now()
+		Exit(3)
+		elapsed := time.Since(start)
+
+		// let the abandoned shutdown finish before the test ends
+		close(release)
+		_shutdowns.Wait()
+
+		if elapsed > 500*time.Millisecond {
+			t.Errorf("Expected Exit to give up at the deadline, took %s", elapsed)
+		}
+		if codes, _ := recorder.get(); codes[len(codes)-1] != 3 {
+			t.Errorf("Expected exit code 3, got %v", codes)
+		}
+	})
+}
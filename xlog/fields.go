@@ -0,0 +1,47 @@
+package xlog
+
+import "fmt"
+
+// BadKey is the field key of a value passed without a key to the *w methods and With
+const BadKey = "!BADKEY"
+
+// ToFields converts alternating keys and values into fields, a key that is not a string is formatted with fmt.Sprint.
+// Loggers implementing ILogger outside this package use it to build the fields of the *w methods and With.
+func ToFields(keysAndValues []interface{}) map[string]interface{} {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	r := make(map[string]interface{}, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i == len(keysAndValues)-1 {
+			r[BadKey] = keysAndValues[i]
+			break
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		r[key] = keysAndValues[i+1]
+	}
+	return r
+}
+
+// MergeFields returns a new map of the union of both field sets, fields overriding base, nil if both are empty.
+// The entries own their fields, a sink or a Redactor changing them does not affect the logger.
+// Loggers implementing ILogger outside this package use it to add the fields of an entry to their own.
+func MergeFields(base, fields map[string]interface{}) map[string]interface{} {
+	if len(base) == 0 && len(fields) == 0 {
+		return nil
+	}
+
+	r := make(map[string]interface{}, len(base)+len(fields))
+	for k, v := range base {
+		r[k] = v
+	}
+	for k, v := range fields {
+		r[k] = v
+	}
+	return r
+}
@@ -1,12 +1,15 @@
 package xlog
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/DreamvatLab/go/xtask"
 	"github.com/kataras/golog"
-	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 )
 
 // shouldShowCaller 判断是否应该显示调用者信息
@@ -26,239 +29,409 @@ func newGologLogger(config *LogConfig, sinks ...LogSink) ILogger {
 	}
 
 	logger := golog.New()
-	if config.Level != "" {
-		logger.SetLevel(config.Level)
-	}
 	logger.SetTimeFormat("2006/01/02 15:04:05")
 
 	// 文件输出（控制台输出用golog默认的，不要AddOutput(os.Stdout)）
+	var fileWriter *RotatingFileWriter
+	var fileErr error
 	if config.File != nil && config.File.Filename != "" {
-		if config.File.MaxSize == 0 {
-			config.File.MaxSize = 10
-		}
-		if config.File.MaxBackups == 0 {
-			config.File.MaxBackups = 5
+		fileWriter, fileErr = NewRotatingFileWriter(config.File)
+		if fileErr == nil {
+			logger.AddOutput(fileWriter)
 		}
-		if config.File.MaxAge == 0 {
-			config.File.MaxAge = 7
+	}
+
+	// 输出格式，控制台和文件共用
+	encoder := config.Encoder
+	var encoderErr error
+	if encoder == nil {
+		encoder, encoderErr = NewEncoder(config.Format, config.Encoding)
+		if encoderErr != nil {
+			encoder = _defaultEncoder
 		}
-		rotationTime := time.Duration(24) * time.Hour
-		writer, err := rotatelogs.New(
-			config.File.Filename+".%Y%m%d%H%M%S",
-			rotatelogs.WithRotationTime(rotationTime),
-			rotatelogs.WithRotationCount(uint(config.File.MaxBackups)),
-		)
-		if err == nil {
-			logger.AddOutput(writer)
+	}
+	logger.RegisterFormatter(&gologFormatter{encoder: encoder})
+	logger.SetFormat("xlog")
+
+	// 异步写入 sinks
+	var asyncSinks []*AsyncSink
+	if config.Async != nil && len(sinks) > 0 {
+		wrapped := make([]LogSink, len(sinks))
+		for i, sink := range sinks {
+			asyncSink := NewAsyncSink(sink, config.Async)
+			asyncSinks = append(asyncSinks, asyncSink)
+			wrapped[i] = asyncSink
 		}
+		sinks = wrapped
 	}
 
-	// sink逻辑
-	if len(sinks) > 0 {
-		logger.Handle(func(log *golog.Log) bool {
-			logEntry := &LogEntry{
-				Level:      convertGologLevel(log.Level),
-				Time:       time.Now(),
-				LoggerName: "golog",
-				Message:    log.Message,
-				Caller:     "",
-				Stack:      "",
-			}
-
-			if len(sinks) == 1 {
-				sinks[0].WriteLog(logEntry)
-			} else {
-				xtask.ParallelRunSlice(len(sinks), sinks, func(sink LogSink) (interface{}, error) {
-					sink.WriteLog(logEntry)
-					return nil, nil
-				})
-			}
+	// 日志级别，golog 放行所有级别，由 log 按名称过滤。
+	// golog reads its level without a lock, so it is never changed once the logger is in use.
+	levels := &levelRegistry{root: golog.InfoLevel, named: map[string]golog.Level{}}
+	levelErr := levels.replace(config.Level, config.Loggers)
+	logger.SetLevel(LogLevelDebug)
+
+	// 采样
+	var logSampler *sampler
+	var samplerErr error
+	if config.Sampling != nil {
+		logSampler, samplerErr = newSampler(config.Sampling)
+	}
 
-			return false // 让golog正常输出
-		})
+	// 脱敏，规则无效时退回默认规则而不是输出原文
+	var redactor Redactor
+	var redactionErr error
+	if config.Redaction != nil {
+		redactor, redactionErr = NewRedactor(config.Redaction)
+		if redactionErr != nil {
+			redactor, _ = NewRedactor(&RedactionConfig{Redactors: config.Redaction.Redactors})
+		}
 	}
 
 	// 解析 TraceLevel
 	_detailLevel := LogLevelMap[config.TraceLevel]
 
-	return &GologLogger{
-		config:      config,
-		innerLogger: logger,
-		sinks:       sinks,
-		detailLevel: _detailLevel,
+	r := &GologLogger{
+		gologCore: &gologCore{
+			innerLogger: logger,
+			sinks:       sinks,
+			asyncSinks:  asyncSinks,
+			encoder:     encoder,
+			fileWriter:  fileWriter,
+			levels:      levels,
+			sampler:     logSampler,
+			redactor:    redactor,
+		},
+	}
+	r.config.Store(config)
+	r.detailLevel.Store(int64(_detailLevel))
+	if logSampler != nil {
+		go logSampler.run(r.logSuppressed)
+	}
+	if redactionErr != nil {
+		r.Warnw("invalid log redaction, using the default rules", "error", redactionErr)
+	}
+	if samplerErr != nil {
+		r.Warnw("invalid log sampling, logging every entry", "error", samplerErr)
+	}
+	if levelErr != nil {
+		r.Warnw("invalid log levels, logging at info level", "error", levelErr)
 	}
+	if fileErr != nil {
+		r.Errorw("failed to open the log file, logging to the console only", "file", config.File.Filename, "error", fileErr)
+	}
+	if encoderErr != nil {
+		r.Warnw("falling back to the text log format", "error", encoderErr)
+	}
+	return r
 }
 
 // GologLogger implements the ILogger interface using the kataras/golog library
 type GologLogger struct {
-	config      *LogConfig
+	*gologCore
+	// name is the LoggerName of the entries, empty for the root logger
+	name string
+	// fields are added to every entry, a child logger created by With holds a copy extended with its own fields
+	fields map[string]interface{}
+	// requestID, traceID and spanID are taken from the context given to WithContext
+	requestID string
+	traceID   string
+	spanID    string
+}
+
+// gologCore is the state shared by a logger and its children
+type gologCore struct {
+	// config and detailLevel are replaced by SetConfig while other goroutines log
+	config      atomic.Pointer[LogConfig]
+	detailLevel atomic.Int64
 	innerLogger *golog.Logger
 	sinks       []LogSink
-	detailLevel int
+	asyncSinks  []*AsyncSink
+	encoder     LogEncoder
+	fileWriter  *RotatingFileWriter
+	levels      *levelRegistry
+	sampler     *sampler
+	redactor    Redactor
 }
 
+// SetConfig applies the levels and the trace level of config, it is safe to call while other goroutines log,
+// e.g. from a configuration change callback
 func (o *GologLogger) SetConfig(config *LogConfig) {
-	o.config = config
-	if config.Level != "" {
-		o.innerLogger.SetLevel(config.Level)
+	o.config.Store(config)
+	if config.Level != "" || config.Loggers != nil {
+		root := config.Level
+		if root == "" {
+			root = formatLevel(o.levels.level(""))
+		}
+		if err := o.levels.replace(root, config.Loggers); err != nil {
+			o.Warnw("invalid log levels, keeping the current ones", "error", err)
+		}
 	}
 	// 更新 detailLevel
 	if config.TraceLevel != "" {
-		o.detailLevel = LogLevelMap[config.TraceLevel]
+		o.detailLevel.Store(int64(LogLevelMap[config.TraceLevel]))
 	}
 }
 
 func (o *GologLogger) GetConfig() *LogConfig {
-	return o.config
-}
-
-func (o *GologLogger) getCallerInfo() string {
-	// _, file, line, ok := runtime.Caller(5)
-	// if ok {
-	// 	return fmt.Sprintf("<%s:%d>", file, line)
-	// }
-	// return ""
-
-	// pcs := make([]uintptr, 32)   // 增大数量可获取更深层栈
-	// n := runtime.Callers(3, pcs) // skip: runtime.Callers, getCallerInfo, Debug
-	// frames := runtime.CallersFrames(pcs[:n])
-
-	// var sb strings.Builder
-	// for {
-	// 	frame, more := frames.Next()
-	// 	file := strings.ToLower(frame.File)
-
-	// 	// ❌ 过滤日志库和标准库路径（根据实际情况调整关键词）
-	// 	if strings.Contains(file, "/dreamvatlab/") ||
-	// 		strings.Contains(file, "runtime/") ||
-	// 		strings.Contains(file, "program files/go") {
-	// 		if !more {
-	// 			break
-	// 		}
-	// 		continue
-	// 	}
-
-	// 	sb.WriteString(fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function))
-	// 	if !more {
-	// 		break
-	// 	}
-	// }
-	// return sb.String()
-	return "# " // 暂不显示调用者信息，因为 golog配合 erros.WithStack 时，%+v 会打印出堆栈信息，导致重复
+	return o.config.Load()
+}
+
+// SetLevel changes the level of a logger name and its children at runtime, the root level if name is empty.
+// An empty level removes the level of the name, which then follows its parent.
+func (o *GologLogger) SetLevel(name, level string) error {
+	return o.levels.set(name, level)
+}
+
+// GetLevel returns the level used by a logger name, the root level if name is empty
+func (o *GologLogger) GetLevel(name string) string {
+	return formatLevel(o.levels.level(name))
+}
+
+// Levels returns the configured levels by logger name, the root level under ""
+func (o *GologLogger) Levels() map[string]string {
+	return o.levels.levels()
+}
+
+func (o *GologLogger) enabled(level golog.Level) bool {
+	return o.levels.level(o.name) >= level
 }
 
 func (o *GologLogger) Debug(v ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelDebug) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Debugf("%s%+v", caller, v)
-			return
-		}
-	}
-	o.innerLogger.Debug(v...)
+	msg := fmt.Sprint(v...)
+	o.log(golog.DebugLevel, msg, nil, v, msg)
 }
 
 func (o *GologLogger) Debugf(format string, args ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelDebug) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Debugf("%s %s", caller, fmt.Sprintf(format, args...))
-			return
-		}
-	}
-	o.innerLogger.Debugf(format, args...)
+	o.log(golog.DebugLevel, fmt.Sprintf(format, args...), nil, args, format)
+}
+
+func (o *GologLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	o.log(golog.DebugLevel, msg, ToFields(keysAndValues), nil, msg)
 }
 
 func (o *GologLogger) Info(v ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelInfo) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Infof("%s%+v", caller, v)
-			return
-		}
-	}
-	o.innerLogger.Info(v...)
+	msg := fmt.Sprint(v...)
+	o.log(golog.InfoLevel, msg, nil, v, msg)
 }
 
 func (o *GologLogger) Infof(format string, args ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelInfo) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Infof("%s %s", caller, fmt.Sprintf(format, args...))
-			return
-		}
-	}
-	o.innerLogger.Infof(format, args...)
+	o.log(golog.InfoLevel, fmt.Sprintf(format, args...), nil, args, format)
+}
+
+func (o *GologLogger) Infow(msg string, keysAndValues ...interface{}) {
+	o.log(golog.InfoLevel, msg, ToFields(keysAndValues), nil, msg)
 }
 
 func (o *GologLogger) Warn(v ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelWarn) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Warnf("%s%+v", caller, v)
-			return
-		}
-	}
-	o.innerLogger.Warn(v...)
+	msg := fmt.Sprint(v...)
+	o.log(golog.WarnLevel, msg, nil, v, msg)
 }
 
 func (o *GologLogger) Warnf(format string, args ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelWarn) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Warnf("%s %s", caller, fmt.Sprintf(format, args...))
-			return
-		}
-	}
-	o.innerLogger.Warnf(format, args...)
+	o.log(golog.WarnLevel, fmt.Sprintf(format, args...), nil, args, format)
+}
+
+func (o *GologLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	o.log(golog.WarnLevel, msg, ToFields(keysAndValues), nil, msg)
 }
 
 func (o *GologLogger) Error(v ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelError) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Errorf("%s%+v", caller, v)
-			return
-		}
-	}
-	o.innerLogger.Error(v...)
+	msg := fmt.Sprint(v...)
+	o.log(golog.ErrorLevel, msg, nil, v, msg)
 }
 
 func (o *GologLogger) Errorf(format string, args ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelError) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Errorf("%s %s", caller, fmt.Sprintf(format, args...))
-			return
-		}
-	}
-	o.innerLogger.Errorf(format, args...)
+	o.log(golog.ErrorLevel, fmt.Sprintf(format, args...), nil, args, format)
+}
+
+func (o *GologLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	o.log(golog.ErrorLevel, msg, ToFields(keysAndValues), nil, msg)
 }
 
 func (o *GologLogger) Fatal(v ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelFatal) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Fatalf("%s%+v", caller, v)
+	msg := fmt.Sprint(v...)
+	o.log(golog.FatalLevel, msg, nil, v, msg)
+	Exit(1)
+}
+
+func (o *GologLogger) Fatalf(format string, args ...interface{}) {
+	o.log(golog.FatalLevel, fmt.Sprintf(format, args...), nil, args, format)
+	Exit(1)
+}
+
+func (o *GologLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	o.log(golog.FatalLevel, msg, ToFields(keysAndValues), nil, msg)
+	Exit(1)
+}
+
+func (o *GologLogger) Named(name string) ILogger {
+	r := *o
+	if o.name != "" && name != "" {
+		r.name = o.name + "." + name
+	} else if name != "" {
+		r.name = name
+	}
+	return &r
+}
+
+func (o *GologLogger) With(keysAndValues ...interface{}) ILogger {
+	return o.WithFields(ToFields(keysAndValues))
+}
+
+func (o *GologLogger) WithFields(fields map[string]interface{}) ILogger {
+	r := *o
+	r.fields = MergeFields(o.fields, fields)
+	return &r
+}
+
+func (o *GologLogger) WithContext(ctx context.Context) ILogger {
+	r := *o
+	r.fields = MergeFields(o.fields, FieldsFromContext(ctx))
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		r.requestID = requestID
+	}
+	if trace, ok := TraceFromContext(ctx); ok {
+		r.traceID, r.spanID = trace.TraceID, trace.SpanID
+	}
+	return &r
+}
+
+// log builds the entry of a message, hands it to the sinks and prints it through golog.
+// values are the arguments of the message, searched with the fields for an error carrying its stack.
+// key groups similar messages for the sampling, entries with an empty key are never sampled.
+func (o *GologLogger) log(level golog.Level, msg string, fields map[string]interface{}, values []interface{}, key string) {
+	if !o.enabled(level) {
+		return
+	}
+	if o.sampler != nil && key != "" && !o.sampler.allow(level, o.name, key) {
+		return
+	}
+
+	entry := &LogEntry{
+		Level:      convertGologLevel(level),
+		Time:       time.Now(),
+		LoggerName: o.name,
+		Message:    msg,
+		RequestID:  o.requestID,
+		TraceID:    o.traceID,
+		SpanID:     o.spanID,
+		Fields:     MergeFields(o.fields, fields),
+	}
+	o.addCaller(entry, level, values)
+	// after addCaller, which needs the errors of the fields for their stack
+	if o.redactor != nil {
+		o.redactor.Redact(entry)
+	}
+
+	o.writeSinks(entry)
+
+	// the formatter encodes the entry itself, with its level.
+	// golog exits on fatal entries, the Fatal methods call Exit instead once the entry is written.
+	if level == golog.FatalLevel {
+		level = golog.ErrorLevel
+	}
+	o.innerLogger.Logf(level, "%s", entry.Message, golog.Fields{_entryField: entry})
+}
+
+// logSuppressed logs the summaries of the entries dropped by the sampling, bypassing it
+func (o *GologLogger) logSuppressed(summaries []suppressedSummary) {
+	for _, summary := range summaries {
+		logger := &GologLogger{gologCore: o.gologCore, name: summary.key.logger}
+		logger.log(summary.key.level, fmt.Sprintf("suppressed %d similar messages", summary.suppressed), map[string]interface{}{
+			"message":    summary.key.message,
+			"suppressed": summary.suppressed,
+		}, nil, "")
+	}
+}
+
+// addCaller sets the caller of an entry and, from TraceLevel on, its stack.
+// The stack recorded by an error of the message or the fields is preferred over the stack of the caller.
+// It is left out when the message already holds it, e.g. Errorf("...: %+v", err), so it is not printed twice.
+func (o *GologLogger) addCaller(entry *LogEntry, level golog.Level, values []interface{}) {
+	withStack := shouldShowCaller(int(o.detailLevel.Load()), level.String())
+	depth := 1
+	if withStack {
+		depth = _maxStackDepth
+	}
+
+	frames := callerFrames(depth)
+	if len(frames) > 0 {
+		entry.Caller = formatCaller(frames[0])
+	}
+	if !withStack {
+		return
+	}
+
+	if stack, ok := firstErrorStack(values); ok {
+		if !strings.Contains(entry.Message, stack) {
+			entry.Stack = stack
+		}
+		return
+	}
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if stack, ok := errorStack(entry.Fields[key]); ok {
+			if !strings.Contains(entry.Message, stack) {
+				entry.Stack = stack
+			}
 			return
 		}
 	}
-	o.innerLogger.Fatal(v...)
+	entry.Stack = formatStack(frames)
 }
 
-func (o *GologLogger) Fatalf(format string, args ...interface{}) {
-	if shouldShowCaller(o.detailLevel, LogLevelFatal) {
-		caller := o.getCallerInfo()
-		if caller != "" {
-			o.innerLogger.Fatalf("%s %s", caller, fmt.Sprintf(format, args...))
-			return
+// writeSinks hands the entry to every sink
+func (o *GologLogger) writeSinks(entry *LogEntry) {
+	if len(o.asyncSinks) > 0 {
+		// queuing does not block unless a queue is full
+		for _, sink := range o.asyncSinks {
+			sink.WriteLog(entry)
 		}
+	} else if len(o.sinks) == 1 {
+		o.sinks[0].WriteLog(entry)
+	} else if len(o.sinks) > 1 {
+		xtask.ParallelRunSlice(len(o.sinks), o.sinks, func(sink LogSink) (interface{}, error) {
+			sink.WriteLog(entry)
+			return nil, nil
+		})
+	}
+}
+
+// DroppedEntries returns the number of entries the async sinks dropped
+func (o *GologLogger) DroppedEntries() uint64 {
+	var r uint64
+	for _, sink := range o.asyncSinks {
+		r += sink.Dropped()
+	}
+	return r
+}
+
+// Flush writes the entries queued for the async sinks and the pending summaries of the sampling
+func (o *GologLogger) Flush() {
+	if o.sampler != nil {
+		o.logSuppressed(o.sampler.drain())
+	}
+	for _, sink := range o.asyncSinks {
+		sink.Flush()
 	}
-	o.innerLogger.Fatalf(format, args...)
 }
 
 func (o *GologLogger) Finalize() {
-	// golog 不需要特殊的清理操作
+	if o.sampler != nil {
+		o.sampler.close()
+	}
+	// 写完队列中的日志
+	for _, sink := range o.asyncSinks {
+		sink.Close()
+	}
+	if o.fileWriter != nil {
+		_ = o.fileWriter.Close()
+	}
 }
 
 func convertGologLevel(level golog.Level) int {
@@ -276,3 +449,19 @@ func convertGologLevel(level golog.Level) int {
 	}
 	return 2 // Info
 }
+
+func toGologLevel(level int) golog.Level {
+	switch level {
+	case 1:
+		return golog.DebugLevel
+	case 2:
+		return golog.InfoLevel
+	case 3:
+		return golog.WarnLevel
+	case 4:
+		return golog.ErrorLevel
+	case 5:
+		return golog.FatalLevel
+	}
+	return golog.InfoLevel
+}
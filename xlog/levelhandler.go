@@ -0,0 +1,214 @@
+package xlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DreamvatLab/go/xhttp"
+	"github.com/kataras/golog"
+)
+
+// _maxLevelRequestSize limits the body of the requests of LevelHandler
+const _maxLevelRequestSize = 64 << 10
+
+// levelRevert is a pending restore of the level of a logger name
+type levelRevert struct {
+	// previous is the level configured before the first temporary change, empty if the name had none
+	previous string
+	at       time.Time
+	timer    *time.Timer
+}
+
+// levelReverts tracks the temporary level changes by logger name
+type levelReverts struct {
+	mu      sync.Mutex
+	pending map[string]*levelRevert
+}
+
+var _levelReverts = &levelReverts{pending: make(map[string]*levelRevert)}
+
+// SetLevelFor changes the level of a logger name of the global logger like SetLevel, then restores the level
+// configured before after d. Successive changes of a name restore the level configured before the first one.
+// The level is not restored if d is not positive.
+func SetLevelFor(name, level string, d time.Duration) error {
+	return _levelReverts.set(name, level, d)
+}
+
+func (o *levelReverts) set(name, level string, d time.Duration) error {
+	l, ok := GetLogger().(levelController)
+	if !ok {
+		return fmt.Errorf("the logger does not support changing levels")
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pending := o.pending[name]
+	previous := l.Levels()[name]
+	if pending != nil {
+		previous = pending.previous
+	}
+	if err := l.SetLevel(name, level); err != nil {
+		return err
+	}
+
+	if pending != nil {
+		pending.timer.Stop()
+		delete(o.pending, name)
+	}
+	if d > 0 {
+		r := &levelRevert{previous: previous, at: time.Now().Add(d)}
+		r.timer = time.AfterFunc(d, func() {
+			o.revert(name, r)
+		})
+		o.pending[name] = r
+	}
+	return nil
+}
+
+// revert restores the level of a name unless the change was replaced since
+func (o *levelReverts) revert(name string, r *levelRevert) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.pending[name] != r {
+		return
+	}
+	delete(o.pending, name)
+
+	if l, ok := GetLogger().(levelController); ok {
+		if err := l.SetLevel(name, r.previous); err != nil {
+			Warnw("failed to restore the log level", "logger", name, "level", r.previous, "error", err)
+			return
+		}
+		Infow("log level restored", "logger", name, "level", r.previous)
+	}
+}
+
+// list returns the pending reverts by logger name
+func (o *levelReverts) list() map[string]levelRevertStatus {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.pending) == 0 {
+		return nil
+	}
+	r := make(map[string]levelRevertStatus, len(o.pending))
+	for name, revert := range o.pending {
+		r[name] = levelRevertStatus{Level: revert.previous, At: revert.at}
+	}
+	return r
+}
+
+// stepLevel makes the root level of the global logger more verbose by step levels, less verbose if negative,
+// within debug and fatal
+func stepLevel(step int, revertAfter time.Duration) (string, error) {
+	current, err := parseLevel(GetLevel(""))
+	if err != nil {
+		return "", err
+	}
+
+	level := int(current) + step
+	level = max(level, int(golog.FatalLevel))
+	level = min(level, int(golog.DebugLevel))
+	name := formatLevel(golog.Level(level))
+	return name, SetLevelFor("", name, revertAfter)
+}
+
+// levelRevertStatus reports a pending revert
+type levelRevertStatus struct {
+	// Level is restored at At, empty to follow the parent logger
+	Level string    `json:"level"`
+	At    time.Time `json:"at"`
+}
+
+// levelStatus reports the levels of the global logger
+type levelStatus struct {
+	Level   string                       `json:"level"`
+	Loggers map[string]string            `json:"loggers"`
+	Reverts map[string]levelRevertStatus `json:"reverts,omitempty"`
+}
+
+// levelRequest changes the level of a logger name, the root level if Logger is empty
+type levelRequest struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level"`
+	// RevertAfter is a duration like "10m" after which the previous level is restored
+	RevertAfter string `json:"revert_after"`
+}
+
+// LevelHandler is an http.Handler reporting and changing the levels of the global logger at runtime,
+// meant for an admin port:
+//
+//	GET  reports {"level": "info", "loggers": {"xsecurity": "debug"}, "reverts": {...}}
+//	PUT  {"logger": "xsecurity", "level": "debug", "revert_after": "10m"} changes a level and reports the levels
+//
+// An empty logger is the root logger, an empty level removes the level of a name, which then follows its parent.
+// POST is accepted like PUT.
+type LevelHandler struct{}
+
+// NewLevelHandler creates a LevelHandler
+func NewLevelHandler() *LevelHandler {
+	return &LevelHandler{}
+}
+
+func (o *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		if status, err := o.setLevel(w, r); err != nil {
+			writeLevelJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		writeLevelJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	levels := Levels()
+	if levels == nil {
+		writeLevelJSON(w, http.StatusNotImplemented, map[string]string{"error": "the logger does not support changing levels"})
+		return
+	}
+	status := levelStatus{Level: levels[""], Loggers: make(map[string]string, len(levels)), Reverts: _levelReverts.list()}
+	for name, level := range levels {
+		if name != "" {
+			status.Loggers[name] = level
+		}
+	}
+	writeLevelJSON(w, http.StatusOK, status)
+}
+
+// setLevel applies a levelRequest, returning the status code of an error
+func (o *LevelHandler) setLevel(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req levelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, _maxLevelRequestSize)).Decode(&req); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid request: %w", err)
+	}
+
+	var revertAfter time.Duration
+	if req.RevertAfter != "" {
+		d, err := time.ParseDuration(req.RevertAfter)
+		if err != nil || d <= 0 {
+			return http.StatusBadRequest, fmt.Errorf("invalid revert_after %q", req.RevertAfter)
+		}
+		revertAfter = d
+	}
+
+	if err := SetLevelFor(req.Logger, req.Level, revertAfter); err != nil {
+		return http.StatusBadRequest, err
+	}
+	Infow("log level changed", "logger", req.Logger, "level", req.Level, "revert_after", req.RevertAfter, "remote_addr", r.RemoteAddr)
+	return 0, nil
+}
+
+// writeLevelJSON writes a JSON response
+func writeLevelJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set(xhttp.HEADER_CTYPE, xhttp.CTYPE_JSON+"; "+xhttp.CHARSET_UTF8)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
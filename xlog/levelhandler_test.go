@@ -0,0 +1,88 @@
+package xlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelHandler(t *testing.T) {
+	defer SetLogger(GetLogger())
+	logger, _, _ := newTestLogger(&LogConfig{Level: LogLevelInfo, Loggers: map[string]string{"xsecurity": LogLevelWarn}})
+	SetLogger(logger)
+
+	handler := NewLevelHandler()
+	serve := func(method, body string) (*httptest.ResponseRecorder, levelStatus) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/log/level", strings.NewReader(body)))
+		var status levelStatus
+		_ = json.Unmarshal(w.Body.Bytes(), &status)
+		return w, status
+	}
+
+	w, status := serve(http.MethodGet, "")
+	if w.Code != http.StatusOK || status.Level != LogLevelInfo || status.Loggers["xsecurity"] != LogLevelWarn {
+		t.Errorf("Unexpected response %d %s", w.Code, w.Body)
+	}
+	if ctype := w.Header().Get("Content-Type"); !strings.HasPrefix(ctype, "application/json") {
+		t.Errorf("Expected a JSON response, got %s", ctype)
+	}
+
+	t.Run("Change", func(t *testing.T) {
+		w, status := serve(http.MethodPut, `{"logger": "xsecurity.auditor", "level": "debug"}`)
+		if w.Code != http.StatusOK || status.Loggers["xsecurity.auditor"] != LogLevelDebug {
+			t.Errorf("Unexpected response %d %s", w.Code, w.Body)
+		}
+		if GetLevel("xsecurity.auditor") != LogLevelDebug {
+			t.Errorf("Expected debug, got %s", GetLevel("xsecurity.auditor"))
+		}
+
+		w, status = serve(http.MethodPost, `{"logger": "xsecurity.auditor"}`)
+		if _, ok := status.Loggers["xsecurity.auditor"]; w.Code != http.StatusOK || ok {
+			t.Errorf("Expected the level removed, got %d %s", w.Code, w.Body)
+		}
+	})
+
+	t.Run("Revert", func(t *testing.T) {
+		w, status := serve(http.MethodPut, `{"level": "debug", "revert_after": "50ms"}`)
+		if w.Code != http.StatusOK || status.Level != LogLevelDebug || status.Reverts[""].Level != LogLevelInfo {
+			t.Fatalf("Unexpected response %d %s", w.Code, w.Body)
+		}
+		// a second change keeps the first level to restore
+		_ = SetLevelFor("", LogLevelWarn, 50*time.Millisecond)
+
+		deadline := time.Now().Add(2 * time.Second)
+		for GetLevel("") != LogLevelInfo && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if GetLevel("") != LogLevelInfo {
+			t.Errorf("Expected the level restored to info, got %s", GetLevel(""))
+		}
+		if _, status := serve(http.MethodGet, ""); len(status.Reverts) != 0 {
+			t.Errorf("Expected no pending revert, got %v", status.Reverts)
+		}
+	})
+
+	t.Run("SetLevel cancels the revert", func(t *testing.T) {
+		_ = SetLevelFor("", LogLevelDebug, 20*time.Millisecond)
+		_ = SetLevel("", LogLevelError)
+		time.Sleep(50 * time.Millisecond)
+		if GetLevel("") != LogLevelError {
+			t.Errorf("Expected error, got %s", GetLevel(""))
+		}
+	})
+
+	t.Run("Bad requests", func(t *testing.T) {
+		for _, body := range []string{`{"level": "verbose"}`, `{"level": "debug", "revert_after": "soon"}`, `not json`} {
+			if w, _ := serve(http.MethodPut, body); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"error"`) {
+				t.Errorf("Expected 400 for %s, got %d %s", body, w.Code, w.Body)
+			}
+		}
+		if w, _ := serve(http.MethodDelete, ""); w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") == "" {
+			t.Errorf("Expected 405, got %d", w.Code)
+		}
+	})
+}
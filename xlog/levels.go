@@ -0,0 +1,141 @@
+package xlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kataras/golog"
+)
+
+// levelRegistry holds the root level and the levels configured per logger name.
+// A named logger uses the level of its name, else of its closest configured parent, e.g. "xsecurity" for
+// "xsecurity.auditor", else the root level.
+type levelRegistry struct {
+	mu    sync.RWMutex
+	root  golog.Level
+	named map[string]golog.Level
+}
+
+// parseLevel converts a level name of LogLevelMap to a golog level
+func parseLevel(level string) (golog.Level, error) {
+	switch strings.ToLower(level) {
+	case LogLevelAll, LogLevelDebug:
+		return golog.DebugLevel, nil
+	case LogLevelInfo:
+		return golog.InfoLevel, nil
+	case LogLevelWarn:
+		return golog.WarnLevel, nil
+	case LogLevelError:
+		return golog.ErrorLevel, nil
+	case LogLevelFatal:
+		return golog.FatalLevel, nil
+	}
+	return golog.DisableLevel, fmt.Errorf("unknown log level %q", level)
+}
+
+// formatLevel returns the name of a golog level in LogLevelMap
+func formatLevel(level golog.Level) string {
+	return LevelName(convertGologLevel(level))
+}
+
+// level returns the level of a logger name
+func (o *levelRegistry) level(name string) golog.Level {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	for name != "" {
+		if level, ok := o.named[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return o.root
+}
+
+// set changes the level of a logger name, the root level if name is empty.
+// An empty level removes the level of the name, which then follows its parent.
+func (o *levelRegistry) set(name, level string) error {
+	if level == "" {
+		if name == "" {
+			return fmt.Errorf("the root log level cannot be removed")
+		}
+		o.mu.Lock()
+		delete(o.named, name)
+		o.mu.Unlock()
+		return nil
+	}
+
+	l, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if name == "" {
+		o.root = l
+	} else {
+		o.named[name] = l
+	}
+	return nil
+}
+
+// replace sets the root level and replaces the levels of the names
+func (o *levelRegistry) replace(root string, named map[string]string) error {
+	r := &levelRegistry{root: golog.InfoLevel, named: make(map[string]golog.Level, len(named))}
+	if root != "" {
+		if err := r.set("", root); err != nil {
+			return err
+		}
+	}
+
+	// sorted for a stable error
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := r.set(name, named[name]); err != nil {
+			return fmt.Errorf("logger %s: %w", name, err)
+		}
+	}
+
+	o.mu.Lock()
+	o.root, o.named = r.root, r.named
+	o.mu.Unlock()
+	return nil
+}
+
+// maxLevel returns the most verbose level in use, the level golog must let through
+func (o *levelRegistry) maxLevel() golog.Level {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	r := o.root
+	for _, level := range o.named {
+		if level > r {
+			r = level
+		}
+	}
+	return r
+}
+
+// levels returns the configured levels by name, the root level under ""
+func (o *levelRegistry) levels() map[string]string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	r := make(map[string]string, len(o.named)+1)
+	r[""] = formatLevel(o.root)
+	for name, level := range o.named {
+		r[name] = formatLevel(level)
+	}
+	return r
+}
@@ -0,0 +1,113 @@
+package xlog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamedLoggers(t *testing.T) {
+	logger, buf, sink := newTestLogger(&LogConfig{
+		Level:   LogLevelInfo,
+		Loggers: map[string]string{"xsecurity": LogLevelDebug, "xredis": LogLevelError},
+	})
+
+	auditor := logger.Named("xsecurity").Named("auditor")
+	redis := logger.Named("xredis")
+
+	auditor.Debug("permission checked")
+	redis.Warn("slow command")
+	logger.Debug("root debug")
+	logger.Info("root info")
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(sink.entries))
+	}
+	if sink.entries[0].LoggerName != "xsecurity.auditor" || sink.entries[1].LoggerName != "" {
+		t.Errorf("Unexpected logger names '%s' and '%s'", sink.entries[0].LoggerName, sink.entries[1].LoggerName)
+	}
+	if !strings.Contains(buf.String(), "[xsecurity.auditor] ") {
+		t.Errorf("Expected the name in the output, got %q", buf.String())
+	}
+
+	t.Run("Levels change at runtime", func(t *testing.T) {
+		if err := logger.SetLevel("xredis", LogLevelDebug); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := logger.SetLevel("xsecurity", ""); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		n := len(sink.entries)
+		redis.Debug("now visible")
+		auditor.Debug("now hidden")
+		if len(sink.entries) != n+1 || sink.entries[n].Message != "now visible" {
+			t.Errorf("Expected only the xredis entry, got %d new entries", len(sink.entries)-n)
+		}
+
+		if level := logger.GetLevel("xsecurity.auditor"); level != LogLevelInfo {
+			t.Errorf("Expected the root level, got '%s'", level)
+		}
+		if levels := logger.Levels(); len(levels) != 2 || levels[""] != LogLevelInfo || levels["xredis"] != LogLevelDebug {
+			t.Errorf("Unexpected levels %v", levels)
+		}
+	})
+
+	t.Run("Invalid levels", func(t *testing.T) {
+		if err := logger.SetLevel("xredis", "verbose"); err == nil {
+			t.Error("Expected error for unknown level")
+		}
+		if err := logger.SetLevel("", ""); err == nil {
+			t.Error("Expected error when removing the root level")
+		}
+	})
+
+	t.Run("SetConfig while logging", func(t *testing.T) {
+		logger, _, sink := newTestLogger(&LogConfig{Level: LogLevelInfo})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				logger.Error("logging")
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			logger.SetConfig(&LogConfig{Level: LogLevelDebug, TraceLevel: LogLevelWarn})
+		}
+		<-done
+
+		logger.Debug("visible")
+		if n := len(sink.entries); n != 101 || sink.entries[n-1].Message != "visible" {
+			t.Errorf("Expected the debug entry after the error entries, got %d entries", n)
+		}
+		if logger.GetConfig().TraceLevel != LogLevelWarn {
+			t.Errorf("Unexpected config %+v", logger.GetConfig())
+		}
+	})
+
+	t.Run("All is debug", func(t *testing.T) {
+		logger, _, sink := newTestLogger(&LogConfig{Level: LogLevelAll})
+		logger.Debug("visible")
+		if len(sink.entries) != 1 {
+			t.Errorf("Expected the debug entry, got %d entries", len(sink.entries))
+		}
+	})
+}
+
+func TestGlobalNamed(t *testing.T) {
+	defer SetLogger(GetLogger())
+
+	// created before the logger is installed, like a package-level variable
+	auditor := Named("xsecurity").Named("auditor")
+
+	logger, _, sink := newTestLogger(&LogConfig{Level: LogLevelInfo, Loggers: map[string]string{"xsecurity": LogLevelDebug}})
+	SetLogger(logger)
+
+	auditor.Debug("permission checked")
+	auditor.With("user_id", 1).Infow("granted")
+	if len(sink.entries) != 2 || sink.entries[0].LoggerName != "xsecurity.auditor" || sink.entries[1].Fields["user_id"] != 1 {
+		t.Fatalf("Expected the entries on the installed logger, got %+v", sink.entries)
+	}
+	if !strings.HasPrefix(sink.entries[0].Caller, "xlog/levels_test.go:") {
+		t.Errorf("Expected the caller in levels_test.go, got %s", sink.entries[0].Caller)
+	}
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+
+package xlog
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// HandleLevelSignals steps the root level of the global logger on SIGUSR1, one level more verbose, and on SIGUSR2,
+// one level less verbose. The level configured before is restored after revertAfter if positive.
+// The returned function stops handling the signals.
+func HandleLevelSignals(revertAfter time.Duration) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case sig := <-signals:
+				step := 1
+				if sig == syscall.SIGUSR2 {
+					step = -1
+				}
+				level, err := stepLevel(step, revertAfter)
+				if err != nil {
+					Warnw("failed to change the log level", "signal", sig.String(), "error", err)
+					continue
+				}
+				Infow("log level changed", "signal", sig.String(), "level", level, "revert_after", revertAfter.String())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+		<-stopped
+	}
+}
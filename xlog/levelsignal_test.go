@@ -0,0 +1,41 @@
+//go:build !windows
+
+package xlog
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleLevelSignals(t *testing.T) {
+	defer SetLogger(GetLogger())
+	logger, _, _ := newTestLogger(&LogConfig{Level: LogLevelInfo})
+	SetLogger(logger)
+
+	stop := HandleLevelSignals(0)
+	defer stop()
+
+	waitLevel := func(expected string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for GetLevel("") != expected && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if level := GetLevel(""); level != expected {
+			t.Fatalf("Expected %s, got %s", expected, level)
+		}
+	}
+
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitLevel(LogLevelDebug)
+	// debug is the most verbose level
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	time.Sleep(20 * time.Millisecond)
+	waitLevel(LogLevelDebug)
+
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitLevel(LogLevelInfo)
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitLevel(LogLevelWarn)
+}
@@ -0,0 +1,8 @@
+package xlog
+
+import "time"
+
+// HandleLevelSignals does nothing on Windows, which has no SIGUSR1 and SIGUSR2
+func HandleLevelSignals(revertAfter time.Duration) (stop func()) {
+	return func() {}
+}
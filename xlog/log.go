@@ -1,6 +1,9 @@
 package xlog
 
 import (
+	"context"
+	"log/slog"
+	"sync/atomic"
 	"time"
 )
 
@@ -24,15 +27,71 @@ var LogLevelMap = map[string]int{
 	LogLevelFatal: 5,
 }
 
-// Global logger instance
-var (
-	// _logger ILogger = newZapLogger(&LogConfig{})
-	_logger ILogger = newGologLogger(&LogConfig{})
-)
+// LevelName returns the name of a LogEntry level, e.g. "info", LogLevelInfo for an unknown level
+func LevelName(level int) string {
+	for name, value := range LogLevelMap {
+		if value == level && name != LogLevelAll {
+			return name
+		}
+	}
+	return LogLevelInfo
+}
+
+// Global logger instance, replaced by Init and SetLogger while other goroutines may be logging
+var _logger atomic.Pointer[ILogger]
 
+func init() {
+	// SetLogger(newZapLogger(&LogConfig{}))
+	SetLogger(newGologLogger(&LogConfig{}))
+}
+
+// Init replaces the global logger with a logger of the configuration and finalizes the previous one,
+// which writes its queued entries and closes its file
 func Init(logConfig *LogConfig, sinks ...LogSink) {
-	// _logger = newZapLogger(logConfig, sinks...)
-	_logger = newGologLogger(logConfig, sinks...)
+	// SetLogger(newZapLogger(logConfig, sinks...))
+	SetShutdownTimeout(logConfig.ShutdownTimeout)
+	var previous ILogger
+	switch logConfig.Backend {
+	case LogBackendSlog:
+		previous = SetLogger(newSlogLogger(logConfig, sinks...))
+	case "", LogBackendGolog:
+		previous = SetLogger(newGologLogger(logConfig, sinks...))
+	default:
+		logger := newGologLogger(logConfig, sinks...)
+		previous = SetLogger(logger)
+		logger.Warnw("unknown log backend, using golog", "backend", logConfig.Backend)
+	}
+	if previous != nil {
+		previous.Finalize()
+	}
+}
+
+// GetLogger returns the global logger
+func GetLogger() ILogger {
+	if r := _logger.Load(); r != nil {
+		return *r
+	}
+	return nil
+}
+
+// SetLogger replaces the global logger, e.g. with a logger of tests, and returns the previous one.
+// Unlike Init, the previous logger is not finalized, the caller can restore it.
+func SetLogger(logger ILogger) ILogger {
+	var p *ILogger
+	if logger != nil {
+		p = &logger
+	}
+	if r := _logger.Swap(p); r != nil {
+		return *r
+	}
+	return nil
+}
+
+// levelController is implemented by the loggers whose levels can be changed at runtime
+type levelController interface {
+	SetLevel(name, level string) error
+	GetLevel(name string) string
+	Levels() map[string]string
 }
 
 // ILogger defines the interface for logging operations
@@ -53,11 +112,32 @@ type ILogger interface {
 	Error(v ...interface{})
 	// Errorf logs a formatted message at error level
 	Errorf(format string, args ...interface{})
-	// Fatal logs a message at fatal level and then calls os.Exit(1)
+	// Fatal logs a message at fatal level and then calls Exit(1)
 	Fatal(v ...interface{})
-	// Fatalf logs a formatted message at fatal level and then calls os.Exit(1)
+	// Fatalf logs a formatted message at fatal level and then calls Exit(1)
 	Fatalf(format string, args ...interface{})
 
+	// Debugw logs a message at debug level with alternating keys and values, e.g. Debugw("msg", "user_id", 1)
+	Debugw(msg string, keysAndValues ...interface{})
+	// Infow logs a message at info level with alternating keys and values
+	Infow(msg string, keysAndValues ...interface{})
+	// Warnw logs a message at warning level with alternating keys and values
+	Warnw(msg string, keysAndValues ...interface{})
+	// Errorw logs a message at error level with alternating keys and values
+	Errorw(msg string, keysAndValues ...interface{})
+	// Fatalw logs a message at fatal level with alternating keys and values and then calls Exit(1)
+	Fatalw(msg string, keysAndValues ...interface{})
+
+	// Named returns a child logger whose entries have the name, appended to the name of this logger with a dot.
+	// Its level can be configured with LogConfig.Loggers or SetLevel.
+	Named(name string) ILogger
+	// With returns a child logger adding the alternating keys and values to every entry
+	With(keysAndValues ...interface{}) ILogger
+	// WithFields returns a child logger adding the fields to every entry
+	WithFields(fields map[string]interface{}) ILogger
+	// WithContext returns a child logger adding the request ID, trace context and fields stored in ctx to every entry
+	WithContext(ctx context.Context) ILogger
+
 	// Finalize performs any necessary cleanup operations for the logger
 	Finalize()
 }
@@ -68,8 +148,33 @@ type LogConfig struct {
 	Level string
 	// TraceLevel sets the minimum level for trace logging
 	TraceLevel string
+	// Loggers sets the level of named loggers and their children, e.g. {"xsecurity": "debug"}
+	Loggers map[string]string
 	// File contains configuration for file-based logging
 	File *FileLogConfig
+	// Format selects the encoder of the console and file output: "text" (default), "json" or "logfmt"
+	Format string
+	// Encoding customizes the time format, level names and field order of the encoder
+	Encoding *EncoderConfig
+	// Encoder replaces the encoder selected by Format
+	Encoder LogEncoder `json:"-"`
+	// Redaction, when set, removes passwords, tokens and other sensitive values from the messages and fields
+	// before they reach the console, the file and the sinks
+	Redaction *RedactionConfig
+	// Sampling, when set, limits the entries logged for the same message
+	Sampling *SamplingConfig
+	// Async, when set, writes to the sinks from background goroutines through bounded queues.
+	// Finalize writes the queued entries.
+	Async *AsyncSinkConfig
+	// ShutdownTimeout bounds the shutdown hooks and the flushing of the logger before a fatal exit,
+	// DefaultShutdownTimeout if not set. See Exit.
+	ShutdownTimeout time.Duration
+	// Backend selects the implementation used by Init: "golog" (default) or "slog".
+	// The slog backend supports Level, Loggers, File, Format, Redaction and Async, it warns about Sampling and TraceLevel
+	// and ignores the other options.
+	Backend string
+	// SlogHandler replaces the console and file output of the slog backend
+	SlogHandler slog.Handler `json:"-"`
 }
 
 // FileLogConfig holds the configuration for file-based logging
@@ -84,6 +189,9 @@ type FileLogConfig struct {
 	MaxAge int
 	// Compress determines if rotated log files should be compressed
 	Compress bool
+	// RotationTime is the period after which a new log file is started, counted from local midnight so that
+	// 24h, the default, rotates at midnight. Negative to rotate on size only.
+	RotationTime time.Duration
 }
 
 type LogSink interface {
@@ -99,12 +207,15 @@ type LogEntry struct {
 	Message    string
 	Caller     string
 	Stack      string
-	// Fields     map[string]interface{}
+	RequestID  string
+	TraceID    string
+	SpanID     string
+	Fields     map[string]interface{}
 }
 
 // WriteLog writes a debug level log message
 func WriteLog(f func(v ...interface{}), v ...interface{}) {
-	if _logger == nil {
+	if GetLogger() == nil {
 		panic("logger is not initialized")
 	}
 
@@ -113,66 +224,156 @@ func WriteLog(f func(v ...interface{}), v ...interface{}) {
 
 // WriteLogf writes a formatted debug level log message
 func WriteLogf(f func(format string, args ...interface{}), format string, args ...interface{}) {
-	if _logger == nil {
+	if GetLogger() == nil {
 		panic("logger is not initialized")
 	}
 
 	f(format, args...)
 }
 
+// WriteLogw writes a log message with alternating keys and values
+func WriteLogw(f func(msg string, keysAndValues ...interface{}), msg string, keysAndValues ...interface{}) {
+	if GetLogger() == nil {
+		panic("logger is not initialized")
+	}
+
+	f(msg, keysAndValues...)
+}
+
 // Debug logs a message at debug level
 func Debug(v ...interface{}) {
-	WriteLog(_logger.Debug, v...)
+	WriteLog(GetLogger().Debug, v...)
 }
 
 // Debugf logs a formatted message at debug level
 func Debugf(format string, args ...interface{}) {
-	WriteLogf(_logger.Debugf, format, args...)
+	WriteLogf(GetLogger().Debugf, format, args...)
 }
 
 // Info logs a message at info level
 func Info(v ...interface{}) {
-	WriteLog(_logger.Info, v...)
+	WriteLog(GetLogger().Info, v...)
 }
 
 // Infof logs a formatted message at info level
 func Infof(format string, args ...interface{}) {
-	WriteLogf(_logger.Infof, format, args...)
+	WriteLogf(GetLogger().Infof, format, args...)
 }
 
 // Warn logs a message at warning level
 func Warn(v ...interface{}) {
-	WriteLog(_logger.Warn, v...)
+	WriteLog(GetLogger().Warn, v...)
 }
 
 // Warnf logs a formatted message at warning level
 func Warnf(format string, args ...interface{}) {
-	WriteLogf(_logger.Warnf, format, args...)
+	WriteLogf(GetLogger().Warnf, format, args...)
 }
 
 // Error logs a message at error level
 func Error(v ...interface{}) {
-	WriteLog(_logger.Error, v...)
+	WriteLog(GetLogger().Error, v...)
 }
 
 // Errorf logs a formatted message at error level
 func Errorf(format string, args ...interface{}) {
-	WriteLogf(_logger.Errorf, format, args...)
+	WriteLogf(GetLogger().Errorf, format, args...)
 }
 
-// Fatal logs a message at fatal level and then calls os.Exit(1)
+// Fatal logs a message at fatal level and then calls Exit(1)
 func Fatal(v ...interface{}) {
-	WriteLog(_logger.Fatal, v...)
+	WriteLog(GetLogger().Fatal, v...)
 }
 
-// Fatalf logs a formatted message at fatal level and then calls os.Exit(1)
+// Fatalf logs a formatted message at fatal level and then calls Exit(1)
 func Fatalf(format string, args ...interface{}) {
-	WriteLogf(_logger.Fatalf, format, args...)
+	WriteLogf(GetLogger().Fatalf, format, args...)
+}
+
+// Debugw logs a message at debug level with alternating keys and values
+func Debugw(msg string, keysAndValues ...interface{}) {
+	WriteLogw(GetLogger().Debugw, msg, keysAndValues...)
+}
+
+// Infow logs a message at info level with alternating keys and values
+func Infow(msg string, keysAndValues ...interface{}) {
+	WriteLogw(GetLogger().Infow, msg, keysAndValues...)
+}
+
+// Warnw logs a message at warning level with alternating keys and values
+func Warnw(msg string, keysAndValues ...interface{}) {
+	WriteLogw(GetLogger().Warnw, msg, keysAndValues...)
+}
+
+// Errorw logs a message at error level with alternating keys and values
+func Errorw(msg string, keysAndValues ...interface{}) {
+	WriteLogw(GetLogger().Errorw, msg, keysAndValues...)
+}
+
+// Fatalw logs a message at fatal level with alternating keys and values and then calls Exit(1)
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	WriteLogw(GetLogger().Fatalw, msg, keysAndValues...)
+}
+
+// Named returns a named child of the global logger.
+// The child resolves the global logger on every entry, so a package-level logger like
+// var log = xlog.Named("xsecurity") follows the logger installed by Init afterwards.
+func Named(name string) ILogger {
+	return &globalNamed{name: name}
+}
+
+// SetLevel changes the level of a logger name of the global logger at runtime, the root level if name is empty.
+// An empty level removes the level of the name, which then follows its parent.
+// A pending revert of SetLevelFor for the name is canceled.
+func SetLevel(name, level string) error {
+	return SetLevelFor(name, level, 0)
+}
+
+// GetLevel returns the level used by a logger name of the global logger, the root level if name is empty
+func GetLevel(name string) string {
+	if l, ok := GetLogger().(levelController); ok {
+		return l.GetLevel(name)
+	}
+	return ""
+}
+
+// Levels returns the configured levels of the global logger by logger name, the root level under ""
+func Levels() map[string]string {
+	if l, ok := GetLogger().(levelController); ok {
+		return l.Levels()
+	}
+	return nil
+}
+
+// With returns a child of the global logger adding the alternating keys and values to every entry
+func With(keysAndValues ...interface{}) ILogger {
+	if GetLogger() == nil {
+		panic("logger is not initialized")
+	}
+
+	return GetLogger().With(keysAndValues...)
+}
+
+// WithFields returns a child of the global logger adding the fields to every entry
+func WithFields(fields map[string]interface{}) ILogger {
+	if GetLogger() == nil {
+		panic("logger is not initialized")
+	}
+
+	return GetLogger().WithFields(fields)
+}
+
+// DroppedEntries returns the number of entries the async sinks of the global logger dropped
+func DroppedEntries() uint64 {
+	if l, ok := GetLogger().(*GologLogger); ok {
+		return l.DroppedEntries()
+	}
+	return 0
 }
 
 // Finalize performs any necessary cleanup operations for the logger
 func Finalize() {
-	if _logger != nil {
-		_logger.Finalize()
+	if GetLogger() != nil {
+		GetLogger().Finalize()
 	}
 }
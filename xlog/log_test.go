@@ -1,6 +1,9 @@
 package xlog
 
 import (
+	"bytes"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -51,3 +54,67 @@ func TestErrorf(t *testing.T) {
 // func TestFatalf(t *testing.T) {
 // 	Fatalf("test fatal message: %s", "formatted")
 // }
+
+// memorySink records the entries it receives
+type memorySink struct {
+	mu      sync.Mutex
+	entries []*LogEntry
+}
+
+func (o *memorySink) WriteLog(entry *LogEntry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = append(o.entries, entry)
+}
+
+// newTestLogger creates a logger writing to a buffer and a memorySink
+func newTestLogger(config *LogConfig) (*GologLogger, *bytes.Buffer, *memorySink) {
+	sink := new(memorySink)
+	logger := newGologLogger(config, sink).(*GologLogger)
+
+	buf := new(bytes.Buffer)
+	logger.innerLogger.SetOutput(buf)
+	return logger, buf, sink
+}
+
+func TestStructuredFields(t *testing.T) {
+	logger, buf, sink := newTestLogger(&LogConfig{Level: "debug"})
+
+	child := logger.With("request_id", "r-1", "user_id", 42)
+	child.Infow("user logged in", "method", "password")
+	child.WithFields(map[string]interface{}{"user_id": 7}).Warn("overridden")
+	logger.Info("no fields")
+	logger.Infow("odd", "dangling")
+
+	if len(sink.entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(sink.entries))
+	}
+
+	fields := sink.entries[0].Fields
+	if fields["request_id"] != "r-1" || fields["user_id"] != 42 || fields["method"] != "password" {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	if v := sink.entries[1].Fields["user_id"]; v != 7 {
+		t.Errorf("Expected child field to override, got %v", v)
+	}
+	if len(sink.entries[2].Fields) != 0 {
+		t.Errorf("Expected no fields on the parent, got %v", sink.entries[2].Fields)
+	}
+	if v := sink.entries[3].Fields[BadKey]; v != "dangling" {
+		t.Errorf("Expected dangling value under %s, got %v", BadKey, sink.entries[3].Fields)
+	}
+
+	// the entries own their fields
+	sink.entries[2].Fields = nil
+	child.Info("no call fields")
+	sink.entries[4].Fields["user_id"] = 0
+	child.Info("unchanged")
+	if v := sink.entries[5].Fields["user_id"]; v != 42 {
+		t.Errorf("Expected the fields of the logger unchanged, got %v", v)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "user logged in") || !strings.Contains(output, "request_id=r-1") {
+		t.Errorf("Expected fields in the output, got %q", output)
+	}
+}
@@ -0,0 +1,112 @@
+package xlog
+
+import (
+	"context"
+
+	"github.com/kataras/golog"
+)
+
+// globalNamed is a named child of the global logger which resolves the global logger on every call,
+// so it follows the loggers installed by Init and SetLogger after its creation
+type globalNamed struct {
+	name string
+}
+
+// logger returns the named child of the current global logger
+func (o *globalNamed) logger() ILogger {
+	return GetLogger().Named(o.name)
+}
+
+func (o *globalNamed) enabled(level golog.Level) bool {
+	if logger, ok := o.logger().(levelEnabler); ok {
+		return logger.enabled(level)
+	}
+	return true
+}
+
+func (o *globalNamed) Debug(v ...interface{}) {
+	o.logger().Debug(v...)
+}
+
+func (o *globalNamed) Debugf(format string, args ...interface{}) {
+	o.logger().Debugf(format, args...)
+}
+
+func (o *globalNamed) Info(v ...interface{}) {
+	o.logger().Info(v...)
+}
+
+func (o *globalNamed) Infof(format string, args ...interface{}) {
+	o.logger().Infof(format, args...)
+}
+
+func (o *globalNamed) Warn(v ...interface{}) {
+	o.logger().Warn(v...)
+}
+
+func (o *globalNamed) Warnf(format string, args ...interface{}) {
+	o.logger().Warnf(format, args...)
+}
+
+func (o *globalNamed) Error(v ...interface{}) {
+	o.logger().Error(v...)
+}
+
+func (o *globalNamed) Errorf(format string, args ...interface{}) {
+	o.logger().Errorf(format, args...)
+}
+
+func (o *globalNamed) Fatal(v ...interface{}) {
+	o.logger().Fatal(v...)
+}
+
+func (o *globalNamed) Fatalf(format string, args ...interface{}) {
+	o.logger().Fatalf(format, args...)
+}
+
+func (o *globalNamed) Debugw(msg string, keysAndValues ...interface{}) {
+	o.logger().Debugw(msg, keysAndValues...)
+}
+
+func (o *globalNamed) Infow(msg string, keysAndValues ...interface{}) {
+	o.logger().Infow(msg, keysAndValues...)
+}
+
+func (o *globalNamed) Warnw(msg string, keysAndValues ...interface{}) {
+	o.logger().Warnw(msg, keysAndValues...)
+}
+
+func (o *globalNamed) Errorw(msg string, keysAndValues ...interface{}) {
+	o.logger().Errorw(msg, keysAndValues...)
+}
+
+func (o *globalNamed) Fatalw(msg string, keysAndValues ...interface{}) {
+	o.logger().Fatalw(msg, keysAndValues...)
+}
+
+func (o *globalNamed) Named(name string) ILogger {
+	if o.name != "" && name != "" {
+		name = o.name + "." + name
+	} else if name == "" {
+		name = o.name
+	}
+	return &globalNamed{name: name}
+}
+
+// With returns a child of the current global logger, the child does not follow later changes of the global logger
+func (o *globalNamed) With(keysAndValues ...interface{}) ILogger {
+	return o.logger().With(keysAndValues...)
+}
+
+// WithFields returns a child of the current global logger, the child does not follow later changes of the global logger
+func (o *globalNamed) WithFields(fields map[string]interface{}) ILogger {
+	return o.logger().WithFields(fields)
+}
+
+// WithContext returns a child of the current global logger, the child does not follow later changes of the global logger
+func (o *globalNamed) WithContext(ctx context.Context) ILogger {
+	return o.logger().WithContext(ctx)
+}
+
+// Finalize does nothing, the global logger is finalized with the package-level Finalize
+func (o *globalNamed) Finalize() {}
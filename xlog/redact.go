@@ -0,0 +1,330 @@
+package xlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// RedactedValue replaces the sensitive values of the entries
+const RedactedValue = "[REDACTED]"
+
+// Patterns of sensitive values found in messages and field values
+const (
+	PatternEmail = `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`
+	// PatternCardNumber matches 13 to 19 digits, the matches failing the Luhn check of card numbers are kept
+	PatternCardNumber  = `\b(?:\d[ -]?){12,18}\d\b`
+	PatternBearerToken = `(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`
+)
+
+// DefaultRedactedFields are the field names redacted when RedactionConfig.Fields is nil
+var DefaultRedactedFields = []string{"password", "passwd", "pwd", "secret", "salt", "token", "cookie", "authorization", "apikey", "accesskey", "privatekey", "credential"}
+
+// DefaultRedactionPatterns are the patterns redacted when RedactionConfig.Patterns is nil
+var DefaultRedactionPatterns = []string{PatternEmail, PatternCardNumber, PatternBearerToken}
+
+// Redactor removes sensitive data from an entry before it is written.
+// The entry owns its Fields map, which the Redactor can modify in place or replace.
+type Redactor interface {
+	Redact(entry *LogEntry)
+}
+
+// RedactionConfig configures the redaction of the entries
+type RedactionConfig struct {
+	// Fields are the field names whose values are redacted. They match whole words of a field name, split on
+	// "_", "-", "." and camel case and compared case-insensitively, e.g. "password" matches "user_password" but
+	// "salt" does not match "salted", and "apikey" matches "X-Api-Key". Nested fields of maps and structs are
+	// included, as are "name=value" and "name: value" pairs in messages. DefaultRedactedFields if nil.
+	Fields []string
+	// Patterns are regular expressions of the values redacted from messages and string fields,
+	// DefaultRedactionPatterns if nil
+	Patterns []string
+	// Replacement replaces the redacted values, RedactedValue if empty
+	Replacement string
+	// Redactors are applied after the fields and patterns
+	Redactors []Redactor `json:"-"`
+}
+
+// fieldRedactor is the Redactor of a RedactionConfig
+type fieldRedactor struct {
+	fields      FieldMatcher
+	patterns    []valuePattern
+	pairs       *regexp.Regexp // name=value pairs in messages
+	replacement string
+	redactors   []Redactor
+}
+
+// NewRedactor creates the Redactor of a RedactionConfig.
+// It fails when a pattern is not a valid regular expression.
+func NewRedactor(config *RedactionConfig) (Redactor, error) {
+	if config == nil {
+		config = new(RedactionConfig)
+	}
+
+	r := &fieldRedactor{
+		replacement: config.Replacement,
+		redactors:   config.Redactors,
+	}
+	if r.replacement == "" {
+		r.replacement = RedactedValue
+	}
+
+	fields := config.Fields
+	if fields == nil {
+		fields = DefaultRedactedFields
+	}
+	r.fields = NewFieldMatcher(fields...)
+	if len(r.fields) > 0 {
+		// the names are checked with the FieldMatcher, e.g. "db_password" or "Set-Cookie"
+		r.pairs = regexp.MustCompile(`([\w.-]+)("?\s*[=:]\s*)("[^"]*"|[^\s,;&]+)`)
+	}
+
+	patterns := config.Patterns
+	if patterns == nil {
+		patterns = DefaultRedactionPatterns
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		p := valuePattern{re: re}
+		if pattern == PatternCardNumber {
+			p.valid = luhnValid
+		}
+		r.patterns = append(r.patterns, p)
+	}
+	return r, nil
+}
+
+func (o *fieldRedactor) Redact(entry *LogEntry) {
+	entry.Message = o.redactString(entry.Message)
+	if len(entry.Fields) > 0 {
+		fields := make(map[string]interface{}, len(entry.Fields))
+		for key, value := range entry.Fields {
+			fields[key] = o.redactField(key, value)
+		}
+		entry.Fields = fields
+	}
+
+	for _, redactor := range o.redactors {
+		redactor.Redact(entry)
+	}
+}
+
+// redactField redacts the value of a field
+func (o *fieldRedactor) redactField(key string, value interface{}) interface{} {
+	if o.fields.Match(key) {
+		return o.replacement
+	}
+	return o.redactValue(value)
+}
+
+// redactValue redacts the strings and the sensitive nested fields of a value.
+// Maps, structs and slices are converted to their JSON form, the way the JSON encoder writes them.
+func (o *fieldRedactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return o.redactString(v)
+	case error:
+		if s := o.redactString(v.Error()); s != v.Error() {
+			return s
+		}
+		return v
+	case fmt.Stringer:
+		if s := o.redactString(v.String()); s != v.String() {
+			return s
+		}
+		return v
+	case map[string]interface{}:
+		r := make(map[string]interface{}, len(v))
+		for key, e := range v {
+			r[key] = o.redactField(key, e)
+		}
+		return r
+	case []interface{}:
+		r := make([]interface{}, len(v))
+		for i, e := range v {
+			r[i] = o.redactValue(e)
+		}
+		return r
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return value
+		}
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return value
+		}
+		if _, ok := generic.(string); ok {
+			// []byte
+			return value
+		}
+		return o.redactValue(generic)
+	}
+	return value
+}
+
+// redactString replaces the sensitive pairs and the patterns of s
+func (o *fieldRedactor) redactString(s string) string {
+	if s == "" {
+		return s
+	}
+	if o.pairs != nil {
+		s = o.redactPairs(s)
+	}
+	for _, p := range o.patterns {
+		s = p.replace(s, o.replacement)
+	}
+	return s
+}
+
+// redactPairs replaces the values of the name=value pairs of s whose name is sensitive
+func (o *fieldRedactor) redactPairs(s string) string {
+	matches := o.pairs.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if !o.fields.Match(s[m[2]:m[3]]) {
+			continue
+		}
+		b.WriteString(s[last:m[6]])
+		b.WriteString(o.replacement)
+		last = m[7]
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// FieldMatcher matches field names against sensitive names with the rules of RedactionConfig.Fields,
+// it is shared with the redaction of configurations so both hide the same keys
+type FieldMatcher [][]string
+
+// NewFieldMatcher creates the FieldMatcher of sensitive names, names without letters or digits are ignored
+func NewFieldMatcher(names ...string) FieldMatcher {
+	var r FieldMatcher
+	for _, name := range names {
+		if words := fieldNameWords(name); len(words) > 0 {
+			r = append(r, words)
+		}
+	}
+	return r
+}
+
+// Match reports whether the words of a field name hold the words of one of the sensitive names
+func (o FieldMatcher) Match(name string) bool {
+	words := fieldNameWords(name)
+	for _, field := range o {
+		if containsWords(words, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldNameWords splits a field name into lowercase words on separators and camel case,
+// e.g. "X-Api-Key" and "xAPIKey" give x, api, key
+func fieldNameWords(name string) []string {
+	var r []string
+	runes := []rune(name)
+	start := -1
+	for i, c := range runes {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			if start >= 0 {
+				r = append(r, strings.ToLower(string(runes[start:i])))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(c) {
+			// "aB" and the last upper case letter of "ABc" start a word
+			prev := runes[i-1]
+			if !unicode.IsUpper(prev) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				r = append(r, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		r = append(r, strings.ToLower(string(runes[start:])))
+	}
+	return r
+}
+
+// containsWords reports whether consecutive words, joined, equal the words of field joined,
+// so "apikey" matches api, key and "api_key" matches apikey
+func containsWords(words, field []string) bool {
+	target := strings.Join(field, "")
+	for i := range words {
+		joined := ""
+		for _, word := range words[i:] {
+			joined += word
+			if joined == target {
+				return true
+			}
+			if len(joined) >= len(target) {
+				break
+			}
+		}
+	}
+	return false
+}
+
+// valuePattern is a pattern of sensitive values, with an optional check of the matches
+type valuePattern struct {
+	re    *regexp.Regexp
+	valid func(match string) bool
+}
+
+// replace replaces the matches of the pattern in s
+func (o valuePattern) replace(s, replacement string) string {
+	if o.valid == nil {
+		return o.re.ReplaceAllLiteralString(s, replacement)
+	}
+	return o.re.ReplaceAllStringFunc(s, func(match string) string {
+		if o.valid(match) {
+			return replacement
+		}
+		return match
+	})
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum of card numbers, ignoring the other characters
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
@@ -0,0 +1,145 @@
+package xlog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testAccount struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	Salt     string
+	Contact  struct {
+		Email string `json:"email"`
+	} `json:"contact"`
+}
+
+type upperRedactor struct{}
+
+func (upperRedactor) Redact(entry *LogEntry) {
+	entry.Message = strings.ReplaceAll(entry.Message, "internal", "[INTERNAL]")
+}
+
+func TestRedactor(t *testing.T) {
+	redactor, err := NewRedactor(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	account := testAccount{Name: "ann", Password: "p4ss", Salt: "s4lt"}
+	account.Contact.Email = "ann@example.com"
+	entry := &LogEntry{
+		Message: "login password=p4ss token: \"a b\" by ann@example.com with Bearer eyJhbGciOi.x-y_z card 4111 1111 1111 1111",
+		Fields: map[string]interface{}{
+			"db_password": "root",
+			"Set-Cookie":  "sid=1",
+			"account":     account,
+			"error":       errors.New("user bob@example.com not found"),
+			"attempts":    3,
+			"tokens_used": 1200,
+			"salted":      true,
+			"apiKey":      "k",
+		},
+	}
+	fields := entry.Fields
+	redactor.Redact(entry)
+
+	t.Run("Message", func(t *testing.T) {
+		expected := `login password=[REDACTED] token: [REDACTED] by [REDACTED] with [REDACTED] card [REDACTED]`
+		if entry.Message != expected {
+			t.Errorf("Expected %q, got %q", expected, entry.Message)
+		}
+	})
+
+	t.Run("Fields", func(t *testing.T) {
+		if entry.Fields["db_password"] != RedactedValue || entry.Fields["Set-Cookie"] != RedactedValue {
+			t.Errorf("Expected sensitive fields redacted, got %v", entry.Fields)
+		}
+		if entry.Fields["error"] != "user [REDACTED] not found" {
+			t.Errorf("Expected the error text redacted, got %v", entry.Fields["error"])
+		}
+		if entry.Fields["attempts"] != 3 || entry.Fields["tokens_used"] != 1200 || entry.Fields["salted"] != true {
+			t.Errorf("Expected the other fields unchanged, got %v", entry.Fields)
+		}
+		if entry.Fields["apiKey"] != RedactedValue {
+			t.Errorf("Expected the camel case field redacted, got %v", entry.Fields["apiKey"])
+		}
+		if fields["db_password"] != "root" {
+			t.Error("Expected the original fields unchanged")
+		}
+	})
+
+	t.Run("Nested fields", func(t *testing.T) {
+		out, ok := entry.Fields["account"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("Expected a map, got %T", entry.Fields["account"])
+		}
+		contact, _ := out["contact"].(map[string]interface{})
+		if out["name"] != "ann" || out["password"] != RedactedValue || out["Salt"] != RedactedValue || contact["email"] != RedactedValue {
+			t.Errorf("Unexpected account %v", out)
+		}
+	})
+
+	t.Run("Ordinary data", func(t *testing.T) {
+		entry := &LogEntry{Message: "order 567312983475245057 created at 1792281600000 with tokens_used=12 salted=true"}
+		redactor.Redact(entry)
+		if entry.Message != "order 567312983475245057 created at 1792281600000 with tokens_used=12 salted=true" {
+			t.Errorf("Expected the message unchanged, got %q", entry.Message)
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		redactor, err := NewRedactor(&RedactionConfig{
+			Fields:      []string{"ssn"},
+			Patterns:    []string{`\d{3}-\d{2}-\d{4}`},
+			Replacement: "***",
+			Redactors:   []Redactor{upperRedactor{}},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		entry := &LogEntry{
+			Message: "internal check of 123-45-6789 for ann@example.com",
+			Fields:  map[string]interface{}{"user_ssn": "x", "password": "p"},
+		}
+		redactor.Redact(entry)
+		if entry.Message != "[INTERNAL] check of *** for ann@example.com" {
+			t.Errorf("Unexpected message %q", entry.Message)
+		}
+		if entry.Fields["user_ssn"] != "***" || entry.Fields["password"] != "p" {
+			t.Errorf("Unexpected fields %v", entry.Fields)
+		}
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		if _, err := NewRedactor(&RedactionConfig{Patterns: []string{"("}}); err == nil {
+			t.Error("Expected error for invalid pattern")
+		}
+	})
+}
+
+func TestLogRedaction(t *testing.T) {
+	logger, buf, sink := newTestLogger(&LogConfig{Level: "debug", Format: LogFormatLogfmt, Redaction: &RedactionConfig{}})
+
+	logger.With("password", "p4ss").Infof("sent to %s", "ann@example.com")
+
+	out := buf.String()
+	if strings.Contains(out, "p4ss") || strings.Contains(out, "ann@example.com") {
+		t.Errorf("Expected the console output redacted, got %q", out)
+	}
+	if !strings.Contains(out, `msg="sent to [REDACTED]"`) || !strings.Contains(out, "password=[REDACTED]") {
+		t.Errorf("Unexpected output %q", out)
+	}
+	if e := sink.entries[0]; e.Message != "sent to [REDACTED]" || e.Fields["password"] != RedactedValue {
+		t.Errorf("Expected the sink entry redacted, got %+v", e)
+	}
+
+	t.Run("Invalid rules fall back to the defaults", func(t *testing.T) {
+		logger, buf, _ := newTestLogger(&LogConfig{Redaction: &RedactionConfig{Patterns: []string{"("}}})
+		logger.Infow("login", "password", "p4ss")
+		if strings.Contains(buf.String(), "p4ss") || !strings.Contains(buf.String(), "password=[REDACTED]") {
+			t.Errorf("Unexpected output %q", buf.String())
+		}
+	})
+}
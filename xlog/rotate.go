@@ -0,0 +1,375 @@
+package xlog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 文件轮转默认值
+const (
+	DefaultMaxSize      = 10 // MB
+	DefaultMaxBackups   = 5
+	DefaultMaxAge       = 7 // days
+	DefaultRotationTime = 24 * time.Hour
+
+	_rotateTimeFormat = "20060102150405"
+	_compressSuffix   = ".gz"
+)
+
+// RotatingFileWriter writes to "<Filename>.<yyyyMMddHHmmss>" files, switching to a new file when the current one
+// reaches MaxSize or RotationTime elapses. Filename is kept as a symlink to the current file.
+// Rotated files are gzipped when Compress is set and removed when last written more than MaxAge days ago
+// or beyond the MaxBackups newest ones.
+//
+// It is safe for concurrent use.
+type RotatingFileWriter struct {
+	filename     string
+	maxSize      int64
+	maxBackups   int
+	maxAge       time.Duration
+	rotationTime time.Duration
+	compress     bool
+	now          func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	current  string
+	size     int64
+	rotateAt time.Time
+	millMu   sync.Mutex
+	millWG   sync.WaitGroup
+}
+
+// NewRotatingFileWriter opens the file writer of a FileLogConfig, applying the defaults to the zero settings.
+// It fails when the directory or the file cannot be created.
+func NewRotatingFileWriter(config *FileLogConfig) (*RotatingFileWriter, error) {
+	if config == nil || config.Filename == "" {
+		return nil, fmt.Errorf("log file name is required")
+	}
+
+	r := &RotatingFileWriter{
+		filename:     config.Filename,
+		maxSize:      int64(orDefault(config.MaxSize, DefaultMaxSize)) * 1024 * 1024,
+		maxBackups:   orDefault(config.MaxBackups, DefaultMaxBackups),
+		maxAge:       time.Duration(orDefault(config.MaxAge, DefaultMaxAge)) * 24 * time.Hour,
+		rotationTime: config.RotationTime,
+		compress:     config.Compress,
+		now:          time.Now,
+	}
+	if r.rotationTime == 0 {
+		r.rotationTime = DefaultRotationTime
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.filename), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := r.backupPlainFile(); err != nil {
+		return nil, err
+	}
+	if err := r.openNew(); err != nil {
+		return nil, err
+	}
+
+	// clean up the files left by previous runs
+	r.startMill(r.now())
+	return r, nil
+}
+
+// orDefault returns v, or def when v is not positive
+func orDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+// Write writes p to the current file, rotating first when p does not fit in it or the rotation time has come
+func (o *RotatingFileWriter) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if (o.size > 0 && o.size+int64(len(p)) > o.maxSize) || (o.rotationTime > 0 && !o.now().Before(o.rotateAt)) {
+		if err := o.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := o.file.Write(p)
+	o.size += int64(n)
+	return n, err
+}
+
+// Rotate switches to a new file
+func (o *RotatingFileWriter) Rotate() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		return os.ErrClosed
+	}
+	return o.rotate()
+}
+
+// Filename returns the path of the current file
+func (o *RotatingFileWriter) Filename() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		return ""
+	}
+	return o.file.Name()
+}
+
+// Close closes the current file and waits for the compression and pruning of the rotated files
+func (o *RotatingFileWriter) Close() error {
+	o.mu.Lock()
+	var err error
+	if o.file != nil {
+		err = o.file.Close()
+		o.file = nil
+	}
+	o.mu.Unlock()
+
+	o.millWG.Wait()
+	return err
+}
+
+// rotate closes the current file, opens a new one and compresses and prunes the old ones in the background
+func (o *RotatingFileWriter) rotate() error {
+	if err := o.file.Close(); err != nil {
+		return err
+	}
+	o.file = nil
+
+	if err := o.openNew(); err != nil {
+		return err
+	}
+
+	o.startMill(o.now())
+	return nil
+}
+
+// openNew creates a file named after the current time and points the symlink to it
+func (o *RotatingFileWriter) openNew() error {
+	now := o.now()
+	name := o.filename + "." + now.Format(_rotateTimeFormat)
+	for i := 1; exists(name) || exists(name+_compressSuffix); i++ {
+		name = o.filename + "." + now.Format(_rotateTimeFormat) + "." + strconv.Itoa(i)
+	}
+
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	o.file = file
+	o.current = name
+	o.size = 0
+	if o.rotationTime > 0 {
+		o.rotateAt = nextRotation(now, o.rotationTime)
+	}
+
+	// symlinks need extra privileges on Windows
+	if runtime.GOOS != "windows" {
+		if err := o.link(name); err != nil {
+			return fmt.Errorf("failed to link log file: %w", err)
+		}
+	}
+	return nil
+}
+
+// nextRotation returns the first boundary after now of the periods of length every starting at local midnight,
+// so a daily rotation happens at midnight in the time zone of now. Whole days are added as calendar days
+// to follow the daylight saving time changes.
+func nextRotation(now time.Time, every time.Duration) time.Time {
+	y, m, d := now.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	if every%(24*time.Hour) == 0 {
+		return midnight.AddDate(0, 0, int(every/(24*time.Hour)))
+	}
+	return midnight.Add((now.Sub(midnight)/every + 1) * every)
+}
+
+// link atomically replaces the symlink at filename by one to name
+func (o *RotatingFileWriter) link(name string) error {
+	tmp := o.filename + ".link"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(name), tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, o.filename)
+}
+
+// backupPlainFile renames a regular file left at filename, e.g. by a previous version, so the symlink can take its place
+func (o *RotatingFileWriter) backupPlainFile() error {
+	info, err := os.Lstat(o.filename)
+	if err != nil || info.Mode()&os.ModeSymlink != 0 || runtime.GOOS == "windows" {
+		return nil
+	}
+	if info.IsDir() {
+		return fmt.Errorf("log file %s is a directory", o.filename)
+	}
+
+	name := o.filename + "." + info.ModTime().Format(_rotateTimeFormat)
+	for i := 1; exists(name) || exists(name+_compressSuffix); i++ {
+		name = o.filename + "." + info.ModTime().Format(_rotateTimeFormat) + "." + strconv.Itoa(i)
+	}
+	if err := os.Rename(o.filename, name); err != nil {
+		return fmt.Errorf("failed to back up log file: %w", err)
+	}
+	return nil
+}
+
+// backup is a rotated file
+type backup struct {
+	name    string
+	time    time.Time
+	seq     int
+	modTime time.Time
+}
+
+// backups returns the rotated files, newest first
+func (o *RotatingFileWriter) backups(current string) ([]backup, error) {
+	entries, err := os.ReadDir(filepath.Dir(o.filename))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(o.filename) + "."
+	var r []backup
+	for _, e := range entries {
+		name := e.Name()
+		path := filepath.Join(filepath.Dir(o.filename), name)
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || path == current {
+			continue
+		}
+
+		// <stamp>[.<seq>][.gz]
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), _compressSuffix)
+		var seq int
+		if i := strings.IndexByte(stamp, '.'); i >= 0 {
+			if seq, err = strconv.Atoi(stamp[i+1:]); err != nil {
+				continue
+			}
+			stamp = stamp[:i]
+		}
+		t, err := time.ParseInLocation(_rotateTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		r = append(r, backup{name: path, time: t, seq: seq, modTime: info.ModTime()})
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		if r[i].time.Equal(r[j].time) {
+			return r[i].seq > r[j].seq
+		}
+		return r[i].time.After(r[j].time)
+	})
+	return r, nil
+}
+
+// startMill runs mill in the background, Close waits for it
+func (o *RotatingFileWriter) startMill(now time.Time) {
+	o.millWG.Add(1)
+	go func() {
+		defer o.millWG.Done()
+		o.mill(now)
+	}()
+}
+
+// mill removes the rotated files beyond MaxBackups or older than MaxAge and compresses the others
+func (o *RotatingFileWriter) mill(now time.Time) {
+	o.millMu.Lock()
+	defer o.millMu.Unlock()
+
+	// the file may have been rotated again since this run was started
+	o.mu.Lock()
+	current := o.current
+	o.mu.Unlock()
+
+	backups, err := o.backups(current)
+	if err != nil {
+		o.millError(err)
+		return
+	}
+
+	cutoff := now.Add(-o.maxAge)
+	for i, b := range backups {
+		if i >= o.maxBackups || b.modTime.Before(cutoff) {
+			if err := os.Remove(b.name); err != nil && !os.IsNotExist(err) {
+				o.millError(err)
+			}
+			continue
+		}
+		if o.compress && !strings.HasSuffix(b.name, _compressSuffix) {
+			if err := compressFile(b.name); err != nil {
+				o.millError(err)
+			}
+		}
+	}
+}
+
+// millError reports a failure to compress or prune, which must not stop the logging
+func (o *RotatingFileWriter) millError(err error) {
+	fmt.Fprintf(os.Stderr, "xlog: failed to clean up rotated log files of %s: %v\n", o.filename, err)
+}
+
+// compressFile gzips name into name.gz, keeping its modification time, and removes name
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(name+_compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(name + _compressSuffix)
+		return err
+	}
+
+	_ = os.Chtimes(name+_compressSuffix, info.ModTime(), info.ModTime())
+	src.Close()
+	return os.Remove(name)
+}
+
+// exists reports whether a file exists at name
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
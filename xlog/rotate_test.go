@@ -0,0 +1,125 @@
+package xlog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "logs", "app.log")
+
+	// a plain file left by an older version becomes a backup
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewRotatingFileWriter(&FileLogConfig{Filename: filename, MaxSize: 1, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	clock := time.Now()
+	w.now = func() time.Time { return clock }
+
+	t.Run("Symlink follows the current file", func(t *testing.T) {
+		if _, err := w.Write([]byte("first\n")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		target, err := os.Readlink(filename)
+		if err != nil || filepath.Join(filepath.Dir(filename), target) != w.Filename() {
+			t.Errorf("Expected link to %s, got %s (%v)", w.Filename(), target, err)
+		}
+	})
+
+	t.Run("Rotates on size", func(t *testing.T) {
+		current := w.Filename()
+		big := []byte(strings.Repeat("x", 1024*1024-1) + "\n")
+		if _, err := w.Write(big); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if w.Filename() == current {
+			t.Error("Expected a new file once the size limit is reached")
+		}
+		data, _ := os.ReadFile(filename)
+		if string(data) != string(big) {
+			t.Errorf("Expected the link to point to the new file, got %d bytes", len(data))
+		}
+	})
+
+	t.Run("Rotates on time", func(t *testing.T) {
+		current := w.Filename()
+		clock = clock.Add(DefaultRotationTime)
+		if _, err := w.Write([]byte("next day\n")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if w.Filename() == current {
+			t.Error("Expected a new file once the rotation time has come")
+		}
+	})
+
+	t.Run("Rotation boundaries are local", func(t *testing.T) {
+		zone := time.FixedZone("UTC+8", 8*3600)
+		now := time.Date(2024, 5, 1, 7, 30, 0, 0, zone) // 23:30 UTC the day before
+		if v := nextRotation(now, 24*time.Hour); !v.Equal(time.Date(2024, 5, 2, 0, 0, 0, 0, zone)) {
+			t.Errorf("Expected the next local midnight, got %v", v)
+		}
+		if v := nextRotation(now, 6*time.Hour); !v.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, zone)) {
+			t.Errorf("Expected 12:00 local time, got %v", v)
+		}
+	})
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Backups are compressed and pruned", func(t *testing.T) {
+		matches, _ := filepath.Glob(filename + ".*")
+
+		// the current file and the two newest backups
+		if len(matches) != 3 {
+			t.Fatalf("Expected 3 files, got %v", matches)
+		}
+		var compressed int
+		for _, m := range matches {
+			if !strings.HasSuffix(m, _compressSuffix) {
+				continue
+			}
+			compressed++
+			f, _ := os.Open(m)
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				t.Fatalf("Expected a gzip file, got %v", err)
+			}
+			if _, err := io.Copy(io.Discard, gz); err != nil {
+				t.Errorf("Expected a valid gzip file, got %v", err)
+			}
+			f.Close()
+		}
+		if compressed != 2 {
+			t.Errorf("Expected 2 compressed backups, got %v", matches)
+		}
+	})
+
+	t.Run("Writes fail once closed", func(t *testing.T) {
+		if _, err := w.Write([]byte("late\n")); err != os.ErrClosed {
+			t.Errorf("Expected ErrClosed, got %v", err)
+		}
+	})
+
+	t.Run("Setup errors are reported", func(t *testing.T) {
+		if _, err := NewRotatingFileWriter(&FileLogConfig{}); err == nil {
+			t.Error("Expected error without file name")
+		}
+		if _, err := NewRotatingFileWriter(&FileLogConfig{Filename: filepath.Join(dir, "logs")}); err == nil {
+			t.Error("Expected error for a directory")
+		}
+	})
+}
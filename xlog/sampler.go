@@ -0,0 +1,226 @@
+package xlog
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kataras/golog"
+)
+
+// 采样默认值
+const (
+	DefaultSamplingInterval = time.Second
+	DefaultSummaryInterval  = time.Minute
+
+	// _maxSampledKeys bounds the memory of the sampler, the keys beyond share a single state
+	_maxSampledKeys = 10000
+)
+
+// SamplingConfig limits the entries logged for the same message, e.g. a warning logged on every failed request.
+//
+// Entries are grouped by level, logger name and message key: the format of Debugf..Fatalf, the message of
+// Debugw..Fatalw and the formatted values of Debug..Fatal. Every SummaryInterval, a "suppressed N similar messages"
+// entry is logged for each key whose entries were dropped.
+type SamplingConfig struct {
+	// Interval is the sampling window, 1 second by default
+	Interval time.Duration
+	// First is the number of entries per key logged in every window, 0 to disable sampling
+	First int
+	// Thereafter logs every Thereafter-th entry of a key beyond First in a window, 0 to drop them all
+	Thereafter int
+	// RateLimit caps the entries per second of a key passing the sampling, 0 for no limit
+	RateLimit float64
+	// Burst is the number of entries of a key allowed at once by RateLimit, RateLimit rounded up by default
+	Burst int
+	// SummaryInterval is the period of the summaries of the suppressed entries, 1 minute by default
+	SummaryInterval time.Duration
+	// Level is the most severe level sampled, warn by default. Error and fatal entries are never dropped by default.
+	Level string
+}
+
+// sampleKey groups similar entries
+type sampleKey struct {
+	level   golog.Level
+	logger  string
+	message string
+}
+
+// sampleState counts the entries of a key
+type sampleState struct {
+	windowStart time.Time
+	count       int
+	tokens      float64
+	refilled    time.Time
+	suppressed  int
+}
+
+// suppressedSummary reports the entries of a key dropped since the last summary
+type suppressedSummary struct {
+	key        sampleKey
+	suppressed int
+}
+
+// sampler decides which entries are logged and tracks the suppressed ones
+type sampler struct {
+	config   SamplingConfig
+	maxLevel golog.Level
+	now      func() time.Time
+
+	mu   sync.Mutex
+	keys map[sampleKey]*sampleState
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// newSampler resolves the defaults of a SamplingConfig
+func newSampler(config *SamplingConfig) (*sampler, error) {
+	r := &sampler{
+		config:   *config,
+		maxLevel: golog.WarnLevel,
+		now:      time.Now,
+		keys:     make(map[sampleKey]*sampleState),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if r.config.Interval <= 0 {
+		r.config.Interval = DefaultSamplingInterval
+	}
+	if r.config.SummaryInterval <= 0 {
+		r.config.SummaryInterval = DefaultSummaryInterval
+	}
+	if r.config.RateLimit > 0 && r.config.Burst <= 0 {
+		r.config.Burst = int(r.config.RateLimit)
+		if float64(r.config.Burst) < r.config.RateLimit {
+			r.config.Burst++
+		}
+	}
+	if r.config.Level != "" {
+		level, err := parseLevel(r.config.Level)
+		if err != nil {
+			return nil, err
+		}
+		r.maxLevel = level
+	}
+	return r, nil
+}
+
+// allow reports whether an entry is logged, counting it as suppressed otherwise
+func (o *sampler) allow(level golog.Level, logger, message string) bool {
+	// golog levels grow with verbosity
+	if level < o.maxLevel {
+		return true
+	}
+
+	now := o.now()
+	key := sampleKey{level: level, logger: logger, message: message}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	state, ok := o.keys[key]
+	if !ok {
+		if len(o.keys) >= _maxSampledKeys {
+			key = sampleKey{level: level}
+			state = o.keys[key]
+		}
+		if state == nil {
+			state = &sampleState{windowStart: now, tokens: float64(o.config.Burst), refilled: now}
+			o.keys[key] = state
+		}
+	}
+
+	if o.sample(state, now) && o.limit(state, now) {
+		return true
+	}
+	state.suppressed++
+	return false
+}
+
+// sample applies First and Thereafter
+func (o *sampler) sample(state *sampleState, now time.Time) bool {
+	if o.config.First <= 0 {
+		return true
+	}
+	if now.Sub(state.windowStart) >= o.config.Interval {
+		state.windowStart = now
+		state.count = 0
+	}
+
+	state.count++
+	if state.count <= o.config.First {
+		return true
+	}
+	return o.config.Thereafter > 0 && (state.count-o.config.First)%o.config.Thereafter == 0
+}
+
+// limit applies RateLimit with a token bucket
+func (o *sampler) limit(state *sampleState, now time.Time) bool {
+	if o.config.RateLimit <= 0 {
+		return true
+	}
+
+	state.tokens += now.Sub(state.refilled).Seconds() * o.config.RateLimit
+	if state.tokens > float64(o.config.Burst) {
+		state.tokens = float64(o.config.Burst)
+	}
+	state.refilled = now
+
+	if state.tokens < 1 {
+		return false
+	}
+	state.tokens--
+	return true
+}
+
+// drain returns the keys with suppressed entries, sorted by message, and forgets the idle keys
+func (o *sampler) drain() []suppressedSummary {
+	now := o.now()
+
+	o.mu.Lock()
+	var r []suppressedSummary
+	for key, state := range o.keys {
+		if state.suppressed > 0 {
+			r = append(r, suppressedSummary{key: key, suppressed: state.suppressed})
+			state.suppressed = 0
+		} else if now.Sub(state.windowStart) >= o.config.Interval && now.Sub(state.refilled) >= o.config.Interval {
+			delete(o.keys, key)
+		}
+	}
+	o.mu.Unlock()
+
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].key.message != r[j].key.message {
+			return r[i].key.message < r[j].key.message
+		}
+		return r[i].key.logger < r[j].key.logger
+	})
+	return r
+}
+
+// run calls summarize every SummaryInterval until stopped
+func (o *sampler) run(summarize func([]suppressedSummary)) {
+	defer close(o.done)
+
+	ticker := time.NewTicker(o.config.SummaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			summarize(o.drain())
+		case <-o.stop:
+			summarize(o.drain())
+			return
+		}
+	}
+}
+
+// close stops run after a last summary
+func (o *sampler) close() {
+	o.stopOnce.Do(func() {
+		close(o.stop)
+	})
+	<-o.done
+}
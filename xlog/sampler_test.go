@@ -0,0 +1,94 @@
+package xlog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/golog"
+)
+
+func TestSampler(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	newTestSampler := func(config *SamplingConfig) *sampler {
+		s, err := newSampler(config)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		s.now = func() time.Time { return now }
+		return s
+	}
+	count := func(s *sampler, n int, message string) int {
+		var r int
+		for i := 0; i < n; i++ {
+			if s.allow(golog.WarnLevel, "", message) {
+				r++
+			}
+		}
+		return r
+	}
+
+	t.Run("First then every Thereafter-th", func(t *testing.T) {
+		s := newTestSampler(&SamplingConfig{First: 3, Thereafter: 10})
+		if n := count(s, 100, "route does not exist"); n != 3+9 {
+			t.Errorf("Expected 12 entries, got %d", n)
+		}
+		if n := count(s, 5, "other"); n != 3 {
+			t.Errorf("Expected keys to be sampled separately, got %d", n)
+		}
+		if !s.allow(golog.ErrorLevel, "", "route does not exist") {
+			t.Error("Expected errors not to be sampled")
+		}
+
+		now = now.Add(time.Second)
+		if n := count(s, 3, "route does not exist"); n != 3 {
+			t.Errorf("Expected a new window, got %d", n)
+		}
+
+		summaries := s.drain()
+		if len(summaries) != 2 || summaries[1].key.message != "route does not exist" || summaries[1].suppressed != 88 {
+			t.Errorf("Unexpected summaries %+v", summaries)
+		}
+		if summaries := s.drain(); len(summaries) != 0 {
+			t.Errorf("Expected the counts to be reset, got %+v", summaries)
+		}
+	})
+
+	t.Run("Rate limit", func(t *testing.T) {
+		s := newTestSampler(&SamplingConfig{RateLimit: 2})
+		if n := count(s, 10, "hot"); n != 2 {
+			t.Errorf("Expected the burst of 2, got %d", n)
+		}
+		now = now.Add(1500 * time.Millisecond)
+		if n := count(s, 10, "hot"); n != 2 {
+			t.Errorf("Expected 2 refilled tokens, got %d", n)
+		}
+	})
+
+	t.Run("Invalid level", func(t *testing.T) {
+		if _, err := newSampler(&SamplingConfig{Level: "verbose"}); err == nil {
+			t.Error("Expected error for unknown level")
+		}
+	})
+}
+
+func TestSampledLogger(t *testing.T) {
+	logger, buf, sink := newTestLogger(&LogConfig{Sampling: &SamplingConfig{First: 2, SummaryInterval: time.Hour}})
+	auditor := logger.Named("xsecurity")
+
+	for i := 0; i < 50; i++ {
+		auditor.Warnf("route: [%d] does not exist", i)
+	}
+	logger.Finalize()
+
+	if len(sink.entries) != 3 {
+		t.Fatalf("Expected 2 entries and a summary, got %d", len(sink.entries))
+	}
+	summary := sink.entries[2]
+	if summary.Message != "suppressed 48 similar messages" || summary.LoggerName != "xsecurity" || summary.Fields["message"] != "route: [%d] does not exist" {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if !strings.Contains(buf.String(), "suppressed 48 similar messages") {
+		t.Errorf("Expected the summary in the output, got %q", buf.String())
+	}
+}
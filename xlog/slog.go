@@ -0,0 +1,451 @@
+package xlog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/kataras/golog"
+)
+
+// 日志后端
+const (
+	LogBackendGolog = "golog"
+	LogBackendSlog  = "slog"
+)
+
+// levelEnabler is implemented by the loggers of this package to skip the disabled records early
+type levelEnabler interface {
+	enabled(level golog.Level) bool
+}
+
+// toSlogLevel converts a golog level to a slog level, fatal being above error
+func toSlogLevel(level golog.Level) slog.Level {
+	switch level {
+	case golog.DebugLevel:
+		return slog.LevelDebug
+	case golog.WarnLevel:
+		return slog.LevelWarn
+	case golog.ErrorLevel:
+		return slog.LevelError
+	case golog.FatalLevel:
+		return slog.LevelError + 4
+	}
+	return slog.LevelInfo
+}
+
+// fromSlogLevel converts a slog level to the closest golog level, records are never fatal
+func fromSlogLevel(level slog.Level) golog.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return golog.DebugLevel
+	case level < slog.LevelWarn:
+		return golog.InfoLevel
+	case level < slog.LevelError:
+		return golog.WarnLevel
+	}
+	return golog.ErrorLevel
+}
+
+// SlogHandler is a slog.Handler writing the records through an xlog logger,
+// so the libraries logging with log/slog share the output, levels and sinks of xlog:
+//
+//	slog.SetDefault(slog.New(xlog.NewSlogHandler(nil)))
+//
+// The attributes become fields, the keys of the attributes of a group being prefixed with its name and a dot.
+type SlogHandler struct {
+	// logger is nil to use the global logger at the time of each record
+	logger ILogger
+	fields map[string]interface{}
+	prefix string
+}
+
+// NewSlogHandler creates a SlogHandler writing through logger, the global logger if nil
+func NewSlogHandler(logger ILogger) *SlogHandler {
+	return &SlogHandler{logger: logger}
+}
+
+func (o *SlogHandler) getLogger() ILogger {
+	if o.logger != nil {
+		return o.logger
+	}
+	return GetLogger()
+}
+
+func (o *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if logger, ok := o.getLogger().(levelEnabler); ok {
+		return logger.enabled(fromSlogLevel(level))
+	}
+	return true
+}
+
+func (o *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(map[string]interface{}, len(o.fields)+record.NumAttrs())
+	for k, v := range o.fields {
+		fields[k] = v
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttr(fields, o.prefix, attr)
+		return true
+	})
+
+	logger := o.getLogger().WithContext(ctx).WithFields(fields)
+	switch fromSlogLevel(record.Level) {
+	case golog.DebugLevel:
+		logger.Debugw(record.Message)
+	case golog.InfoLevel:
+		logger.Infow(record.Message)
+	case golog.WarnLevel:
+		logger.Warnw(record.Message)
+	default:
+		logger.Errorw(record.Message)
+	}
+	return nil
+}
+
+func (o *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return o
+	}
+	fields := make(map[string]interface{}, len(o.fields)+len(attrs))
+	for k, v := range o.fields {
+		fields[k] = v
+	}
+	for _, attr := range attrs {
+		addAttr(fields, o.prefix, attr)
+	}
+	return &SlogHandler{logger: o.logger, fields: fields, prefix: o.prefix}
+}
+
+func (o *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return o
+	}
+	return &SlogHandler{logger: o.logger, fields: o.fields, prefix: o.prefix + name + "."}
+}
+
+// addAttr adds an attribute to fields, flattening the groups
+func addAttr(fields map[string]interface{}, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		fields[prefix+attr.Key] = attr.Value.Any()
+		return
+	}
+
+	// the attributes of a group without key are inlined
+	if attr.Key != "" {
+		prefix += attr.Key + "."
+	}
+	for _, a := range attr.Value.Group() {
+		addAttr(fields, prefix, a)
+	}
+}
+
+// SlogLogger implements the ILogger interface on top of a slog.Handler.
+// Init uses it when LogConfig.Backend is "slog". The logger name, correlation IDs and fields become attributes.
+type SlogLogger struct {
+	*slogCore
+	// name is the LoggerName of the entries, empty for the root logger
+	name string
+	// fields are added to every entry
+	fields map[string]interface{}
+	// requestID, traceID and spanID are taken from the context given to WithContext
+	requestID string
+	traceID   string
+	spanID    string
+}
+
+// slogCore is the state shared by a logger and its children
+type slogCore struct {
+	handler    slog.Handler
+	sinks      []LogSink
+	asyncSinks []*AsyncSink
+	levels     *levelRegistry
+	fileWriter *RotatingFileWriter
+	redactor   Redactor
+}
+
+// NewSlogLogger creates an ILogger writing to handler, which decides of the level of the entries
+func NewSlogLogger(handler slog.Handler) *SlogLogger {
+	return &SlogLogger{
+		slogCore: &slogCore{
+			handler: handler,
+			levels:  &levelRegistry{root: golog.DebugLevel, named: map[string]golog.Level{}},
+		},
+	}
+}
+
+// newSlogLogger creates the SlogLogger of a LogConfig.
+// Without LogConfig.SlogHandler, it writes to the console and the file with the text or JSON handler of log/slog.
+// Redaction and Async apply as with golog, Sampling and TraceLevel are not supported.
+func newSlogLogger(config *LogConfig, sinks ...LogSink) ILogger {
+	var fileWriter *RotatingFileWriter
+	var fileErr error
+	handler := config.SlogHandler
+	if handler == nil {
+		var w io.Writer = os.Stdout
+		if config.File != nil && config.File.Filename != "" {
+			fileWriter, fileErr = NewRotatingFileWriter(config.File)
+			if fileErr == nil {
+				w = io.MultiWriter(os.Stdout, fileWriter)
+			}
+		}
+
+		// the levels are filtered by the logger
+		options := &slog.HandlerOptions{Level: slog.LevelDebug}
+		if config.Format == LogFormatJSON {
+			handler = slog.NewJSONHandler(w, options)
+		} else {
+			handler = slog.NewTextHandler(w, options)
+		}
+	}
+
+	var asyncSinks []*AsyncSink
+	if config.Async != nil && len(sinks) > 0 {
+		wrapped := make([]LogSink, len(sinks))
+		for i, sink := range sinks {
+			asyncSink := NewAsyncSink(sink, config.Async)
+			asyncSinks = append(asyncSinks, asyncSink)
+			wrapped[i] = asyncSink
+		}
+		sinks = wrapped
+	}
+
+	levels := &levelRegistry{root: golog.InfoLevel, named: map[string]golog.Level{}}
+	levelErr := levels.replace(config.Level, config.Loggers)
+
+	var redactor Redactor
+	var redactionErr error
+	if config.Redaction != nil {
+		redactor, redactionErr = NewRedactor(config.Redaction)
+		if redactionErr != nil {
+			redactor, _ = NewRedactor(&RedactionConfig{Redactors: config.Redaction.Redactors})
+		}
+	}
+
+	r := &SlogLogger{
+		slogCore: &slogCore{
+			handler:    handler,
+			sinks:      sinks,
+			asyncSinks: asyncSinks,
+			levels:     levels,
+			fileWriter: fileWriter,
+			redactor:   redactor,
+		},
+	}
+	if redactionErr != nil {
+		r.Warnw("invalid log redaction, using the default rules", "error", redactionErr)
+	}
+	if config.Sampling != nil {
+		r.Warnw("log sampling is not supported by the slog backend, logging every entry")
+	}
+	if config.TraceLevel != "" {
+		r.Warnw("log stacks are not supported by the slog backend, ignoring the trace level", "trace_level", config.TraceLevel)
+	}
+	if levelErr != nil {
+		r.Warnw("invalid log levels, logging at info level", "error", levelErr)
+	}
+	if fileErr != nil {
+		r.Errorw("failed to open the log file, logging to the console only", "file", config.File.Filename, "error", fileErr)
+	}
+	return r
+}
+
+// SetLevel changes the level of a logger name and its children at runtime, the root level if name is empty.
+// An empty level removes the level of the name, which then follows its parent.
+func (o *SlogLogger) SetLevel(name, level string) error {
+	return o.levels.set(name, level)
+}
+
+// GetLevel returns the level used by a logger name, the root level if name is empty
+func (o *SlogLogger) GetLevel(name string) string {
+	return formatLevel(o.levels.level(name))
+}
+
+// Levels returns the configured levels by logger name, the root level under ""
+func (o *SlogLogger) Levels() map[string]string {
+	return o.levels.levels()
+}
+
+func (o *SlogLogger) enabled(level golog.Level) bool {
+	return o.levels.level(o.name) >= level
+}
+
+func (o *SlogLogger) Debug(v ...interface{}) {
+	o.log(golog.DebugLevel, fmt.Sprint(v...), nil)
+}
+
+func (o *SlogLogger) Debugf(format string, args ...interface{}) {
+	o.log(golog.DebugLevel, fmt.Sprintf(format, args...), nil)
+}
+
+func (o *SlogLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	o.log(golog.DebugLevel, msg, ToFields(keysAndValues))
+}
+
+func (o *SlogLogger) Info(v ...interface{}) {
+	o.log(golog.InfoLevel, fmt.Sprint(v...), nil)
+}
+
+func (o *SlogLogger) Infof(format string, args ...interface{}) {
+	o.log(golog.InfoLevel, fmt.Sprintf(format, args...), nil)
+}
+
+func (o *SlogLogger) Infow(msg string, keysAndValues ...interface{}) {
+	o.log(golog.InfoLevel, msg, ToFields(keysAndValues))
+}
+
+func (o *SlogLogger) Warn(v ...interface{}) {
+	o.log(golog.WarnLevel, fmt.Sprint(v...), nil)
+}
+
+func (o *SlogLogger) Warnf(format string, args ...interface{}) {
+	o.log(golog.WarnLevel, fmt.Sprintf(format, args...), nil)
+}
+
+func (o *SlogLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	o.log(golog.WarnLevel, msg, ToFields(keysAndValues))
+}
+
+func (o *SlogLogger) Error(v ...interface{}) {
+	o.log(golog.ErrorLevel, fmt.Sprint(v...), nil)
+}
+
+func (o *SlogLogger) Errorf(format string, args ...interface{}) {
+	o.log(golog.ErrorLevel, fmt.Sprintf(format, args...), nil)
+}
+
+func (o *SlogLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	o.log(golog.ErrorLevel, msg, ToFields(keysAndValues))
+}
+
+func (o *SlogLogger) Fatal(v ...interface{}) {
+	o.log(golog.FatalLevel, fmt.Sprint(v...), nil)
+	Exit(1)
+}
+
+func (o *SlogLogger) Fatalf(format string, args ...interface{}) {
+	o.log(golog.FatalLevel, fmt.Sprintf(format, args...), nil)
+	Exit(1)
+}
+
+func (o *SlogLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	o.log(golog.FatalLevel, msg, ToFields(keysAndValues))
+	Exit(1)
+}
+
+func (o *SlogLogger) Named(name string) ILogger {
+	r := *o
+	if o.name != "" && name != "" {
+		r.name = o.name + "." + name
+	} else if name != "" {
+		r.name = name
+	}
+	return &r
+}
+
+func (o *SlogLogger) With(keysAndValues ...interface{}) ILogger {
+	return o.WithFields(ToFields(keysAndValues))
+}
+
+func (o *SlogLogger) WithFields(fields map[string]interface{}) ILogger {
+	r := *o
+	r.fields = MergeFields(o.fields, fields)
+	return &r
+}
+
+func (o *SlogLogger) WithContext(ctx context.Context) ILogger {
+	r := *o
+	r.fields = MergeFields(o.fields, FieldsFromContext(ctx))
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		r.requestID = requestID
+	}
+	if trace, ok := TraceFromContext(ctx); ok {
+		r.traceID, r.spanID = trace.TraceID, trace.SpanID
+	}
+	return &r
+}
+
+// log hands a record to the handler and the entry to the sinks
+func (o *SlogLogger) log(level golog.Level, msg string, fields map[string]interface{}) {
+	if !o.enabled(level) {
+		return
+	}
+
+	ctx := context.Background()
+	handled := o.handler.Enabled(ctx, toSlogLevel(level))
+	if !handled && len(o.sinks) == 0 {
+		return
+	}
+
+	entry := &LogEntry{
+		Level:      convertGologLevel(level),
+		Time:       time.Now(),
+		LoggerName: o.name,
+		Message:    msg,
+		RequestID:  o.requestID,
+		TraceID:    o.traceID,
+		SpanID:     o.spanID,
+		Fields:     MergeFields(o.fields, fields),
+	}
+	if o.redactor != nil {
+		o.redactor.Redact(entry)
+	}
+
+	if handled {
+		record := slog.NewRecord(entry.Time, toSlogLevel(level), entry.Message, callerPC())
+		if entry.LoggerName != "" {
+			record.AddAttrs(slog.String(LoggerKey, entry.LoggerName))
+		}
+		for _, id := range [...][2]string{{RequestIDKey, entry.RequestID}, {TraceIDKey, entry.TraceID}, {SpanIDKey, entry.SpanID}} {
+			if id[1] != "" {
+				record.AddAttrs(slog.String(id[0], id[1]))
+			}
+		}
+
+		keys := make([]string, 0, len(entry.Fields))
+		for key := range entry.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			record.AddAttrs(slog.Any(key, entry.Fields[key]))
+		}
+		if err := o.handler.Handle(ctx, record); err != nil {
+			fmt.Fprintf(os.Stderr, "xlog: the slog handler failed to write an entry: %v\n", err)
+		}
+	}
+
+	if len(o.sinks) == 0 {
+		return
+	}
+	if frames := callerFrames(1); len(frames) > 0 {
+		entry.Caller = formatCaller(frames[0])
+	}
+	for _, sink := range o.sinks {
+		sink.WriteLog(entry)
+	}
+}
+
+// Flush writes the entries queued for the async sinks
+func (o *SlogLogger) Flush() {
+	for _, sink := range o.asyncSinks {
+		sink.Flush()
+	}
+}
+
+func (o *SlogLogger) Finalize() {
+	for _, sink := range o.asyncSinks {
+		sink.Close()
+	}
+	if o.fileWriter != nil {
+		_ = o.fileWriter.Close()
+	}
+}
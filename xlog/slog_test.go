@@ -0,0 +1,126 @@
+package xlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	logger, _, sink := newTestLogger(&LogConfig{Level: "info"})
+	l := slog.New(NewSlogHandler(logger.Named("lib")))
+
+	ctx := ContextWithRequestID(context.Background(), "r-1")
+	l.With("a", 1).WithGroup("g").InfoContext(ctx, "hello", "k", "v", slog.Group("sub", "x", 2), slog.Group("", "inline", true))
+	l.Debug("filtered")
+	l.Log(ctx, slog.LevelError+8, "severe")
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(sink.entries))
+	}
+
+	e := sink.entries[0]
+	if e.Level != LogLevelMap[LogLevelInfo] || e.Message != "hello" || e.LoggerName != "lib" || e.RequestID != "r-1" {
+		t.Errorf("Unexpected entry %+v", e)
+	}
+	if e.Fields["a"] != int64(1) || e.Fields["g.k"] != "v" || e.Fields["g.sub.x"] != int64(2) || e.Fields["g.inline"] != true {
+		t.Errorf("Unexpected fields %v", e.Fields)
+	}
+	if !strings.HasPrefix(e.Caller, "xlog/slog_test.go:") {
+		t.Errorf("Expected the caller in slog_test.go, got %s", e.Caller)
+	}
+	if sink.entries[1].Level != LogLevelMap[LogLevelError] {
+		t.Errorf("Expected error level above slog.LevelError, got %d", sink.entries[1].Level)
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := NewSlogLogger(slog.NewJSONHandler(buf, &slog.HandlerOptions{AddSource: true}))
+
+	ctx := ContextWithRequestID(context.Background(), "r-1")
+	logger.Named("db").WithContext(ctx).With("table", "users").Infow("slow query", "ms", 1200)
+	logger.Debug("below the level of the handler")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %q", buf.String())
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &out); err != nil {
+		t.Fatalf("Expected a JSON line, got %q", lines[0])
+	}
+	if out["msg"] != "slow query" || out["level"] != "INFO" || out[LoggerKey] != "db" || out[RequestIDKey] != "r-1" ||
+		out["table"] != "users" || out["ms"] != float64(1200) {
+		t.Errorf("Unexpected output %v", out)
+	}
+	source, _ := out["source"].(map[string]interface{})
+	if file, _ := source["file"].(string); !strings.HasSuffix(file, "slog_test.go") {
+		t.Errorf("Expected the source in slog_test.go, got %v", out["source"])
+	}
+
+	t.Run("Levels", func(t *testing.T) {
+		buf.Reset()
+		if err := logger.SetLevel("", LogLevelWarn); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		_ = logger.SetLevel("db", LogLevelInfo)
+		logger.Info("filtered")
+		logger.Named("db").Info("kept")
+		if strings.Contains(buf.String(), "filtered") || !strings.Contains(buf.String(), "kept") {
+			t.Errorf("Unexpected output %q", buf.String())
+		}
+	})
+}
+
+func TestSlogBackend(t *testing.T) {
+	defer SetLogger(GetLogger())
+
+	buf := new(bytes.Buffer)
+	sink := new(memorySink)
+	Init(&LogConfig{Backend: LogBackendSlog, Level: LogLevelWarn, SlogHandler: slog.NewTextHandler(buf, nil)}, sink)
+
+	Info("filtered")
+	Warnw("disk almost full", "used", 0.93)
+	if !strings.Contains(buf.String(), `level=WARN msg="disk almost full" used=0.93`) || strings.Contains(buf.String(), "filtered") {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+	if len(sink.entries) != 1 || sink.entries[0].Fields["used"] != 0.93 || !strings.HasPrefix(sink.entries[0].Caller, "xlog/slog_test.go:") {
+		t.Errorf("Unexpected entries %+v", sink.entries)
+	}
+
+	if err := SetLevel("", LogLevelInfo); err != nil || GetLevel("") != LogLevelInfo {
+		t.Errorf("Expected the level to change, got %s (%v)", GetLevel(""), err)
+	}
+
+	t.Run("Redaction and async sinks", func(t *testing.T) {
+		buf.Reset()
+		sink := &blockingSink{release: make(chan struct{})}
+		close(sink.release)
+		Init(&LogConfig{
+			Backend:     LogBackendSlog,
+			SlogHandler: slog.NewTextHandler(buf, nil),
+			Redaction:   &RedactionConfig{},
+			Async:       &AsyncSinkConfig{},
+			Sampling:    &SamplingConfig{},
+		}, sink)
+
+		if !strings.Contains(buf.String(), "log sampling is not supported") {
+			t.Errorf("Expected a warning about the sampling, got %q", buf.String())
+		}
+		Infow("login", "password", "p4ss", "email", "ann@example.com")
+		Finalize()
+		if strings.Contains(buf.String(), "p4ss") || strings.Contains(buf.String(), "ann@example.com") {
+			t.Errorf("Expected the handler output redacted, got %q", buf.String())
+		}
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		last := sink.batches[len(sink.batches)-1]
+		if entry := last[len(last)-1]; entry.Message != "login" || entry.Fields["password"] != RedactedValue {
+			t.Errorf("Expected the redacted entry written by the async sink, got %+v", entry)
+		}
+	})
+}
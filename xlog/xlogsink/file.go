@@ -0,0 +1,49 @@
+package xlogsink
+
+import (
+	"sync"
+
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// FileSink writes entries as JSON lines to a file rotated like the file output of xlog
+type FileSink struct {
+	writer  *xlog.RotatingFileWriter
+	encoder xlog.LogEncoder
+	mu      sync.Mutex
+}
+
+// NewFileSink opens the file of a FileLogConfig, encoding is optional.
+//
+// Returns an error if the file cannot be created.
+func NewFileSink(file *xlog.FileLogConfig, encoding *xlog.EncoderConfig) (*FileSink, error) {
+	writer, err := xlog.NewRotatingFileWriter(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{
+		writer:  writer,
+		encoder: newJSONEncoder(encoding),
+	}, nil
+}
+
+// WriteLog appends the entry to the file
+func (o *FileSink) WriteLog(entry *xlog.LogEntry) {
+	o.WriteLogs([]*xlog.LogEntry{entry})
+}
+
+// WriteLogs appends the entries to the file in a single write
+func (o *FileSink) WriteLogs(entries []*xlog.LogEntry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, err := o.writer.Write(encodeLines(o.encoder, entries)); err != nil {
+		reportError("file sink", err)
+	}
+}
+
+// Close closes the file
+func (o *FileSink) Close() error {
+	return o.writer.Close()
+}
@@ -0,0 +1,132 @@
+package xlogsink
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/DreamvatLab/go/xerr"
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// HTTPSinkOptions holds the configuration of an HTTPSink
+type HTTPSinkOptions struct {
+	// URL is the endpoint receiving the entries
+	URL string
+	// Method is the HTTP method, POST if empty
+	Method string
+	// Headers are added to every request, e.g. an Authorization header
+	Headers map[string]string
+	// Timeout limits every attempt, 10 seconds if zero
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a failed request, 3 if zero, negative to never retry
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled after every retry, 500 milliseconds if zero
+	RetryBackoff time.Duration
+	// Client sends the requests, a new client if nil
+	Client *http.Client
+	// Encoding customizes the JSON of the entries
+	Encoding *xlog.EncoderConfig
+}
+
+// HTTPSink posts batches of entries to an endpoint as newline delimited JSON (application/x-ndjson).
+//
+// Requests failing with a network error, 429 or a 5xx status are retried with exponential backoff,
+// the batch is dropped once the retries are exhausted.
+type HTTPSink struct {
+	options HTTPSinkOptions
+	encoder xlog.LogEncoder
+	client  *http.Client
+	failed  atomic.Uint64
+}
+
+// NewHTTPSink creates an HTTPSink.
+//
+// Returns an error if the URL is empty.
+func NewHTTPSink(options *HTTPSinkOptions) (*HTTPSink, error) {
+	if options == nil || options.URL == "" {
+		return nil, xerr.New("http sink url cannot be empty")
+	}
+
+	r := &HTTPSink{
+		options: *options,
+		encoder: newJSONEncoder(options.Encoding),
+		client:  options.Client,
+	}
+	if r.options.Method == "" {
+		r.options.Method = http.MethodPost
+	}
+	if r.options.Timeout <= 0 {
+		r.options.Timeout = 10 * time.Second
+	}
+	if r.options.MaxRetries == 0 {
+		r.options.MaxRetries = 3
+	}
+	if r.options.RetryBackoff <= 0 {
+		r.options.RetryBackoff = 500 * time.Millisecond
+	}
+	if r.client == nil {
+		r.client = &http.Client{Timeout: r.options.Timeout}
+	}
+	return r, nil
+}
+
+// WriteLog posts a single entry
+func (o *HTTPSink) WriteLog(entry *xlog.LogEntry) {
+	o.WriteLogs([]*xlog.LogEntry{entry})
+}
+
+// WriteLogs posts the entries in a single request
+func (o *HTTPSink) WriteLogs(entries []*xlog.LogEntry) {
+	body := encodeLines(o.encoder, entries)
+
+	backoff := o.options.RetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = o.post(body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= o.options.MaxRetries {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	o.failed.Add(uint64(len(entries)))
+	reportError("http sink", xerr.WithMessagef(err, "dropped %d entries", len(entries)))
+}
+
+// Failed returns the number of entries dropped after the retries were exhausted
+func (o *HTTPSink) Failed() uint64 {
+	return o.failed.Load()
+}
+
+// post sends a request, it reports whether a failure is worth retrying
+func (o *HTTPSink) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(o.options.Method, o.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for key, value := range o.options.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = xerr.Errorf("%s %s: %s", o.options.Method, o.options.URL, resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
@@ -0,0 +1,36 @@
+// Package xlogsink provides ready-made xlog.LogSink implementations: a JSON-lines file, an HTTP batch endpoint,
+// an RFC 5424 syslog server and a Redis stream.
+//
+// The sinks write synchronously. Register them with xlog.LogConfig.Async set, so slow destinations do not stall
+// the callers and the HTTP and Redis sinks receive the queued entries in batches.
+//
+// Sinks cannot log their own failures through xlog without looping, so they print them to stderr.
+package xlogsink
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// newJSONEncoder creates the JSON encoder of a sink
+func newJSONEncoder(encoding *xlog.EncoderConfig) xlog.LogEncoder {
+	encoder, _ := xlog.NewEncoder(xlog.LogFormatJSON, encoding)
+	return encoder
+}
+
+// encodeLines encodes entries as JSON lines
+func encodeLines(encoder xlog.LogEncoder, entries []*xlog.LogEntry) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		_ = encoder.Encode(&buf, entry)
+	}
+	return buf.Bytes()
+}
+
+// reportError prints a sink failure to stderr
+func reportError(sink string, err error) {
+	fmt.Fprintf(os.Stderr, "xlogsink: %s: %v\n", sink, err)
+}
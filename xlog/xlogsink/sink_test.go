@@ -0,0 +1,233 @@
+package xlogsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DreamvatLab/go/xlog"
+	"github.com/DreamvatLab/go/xredis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func testEntries() []*xlog.LogEntry {
+	t := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	return []*xlog.LogEntry{
+		{Level: 2, Time: t, Message: "started", Fields: map[string]interface{}{"port": 8080}},
+		{Level: 4, Time: t, Message: "failed", RequestID: "r-1", Fields: map[string]interface{}{"path": `a "b" ]`}},
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.jsonl")
+	sink, err := NewFileSink(&xlog.FileLogConfig{Filename: filename}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries := testEntries()
+	sink.WriteLog(entries[0])
+	sink.WriteLogs(entries[1:])
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", data)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &out); err != nil || out["msg"] != "failed" || out["request_id"] != "r-1" {
+		t.Errorf("Unexpected line %s (%v)", lines[1], err)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var calls atomic.Int32
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first attempt fails
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/x-ndjson" || r.Header.Get("Authorization") != "Bearer t" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(&HTTPSinkOptions{
+		URL:          server.URL,
+		Headers:      map[string]string{"Authorization": "Bearer t"},
+		RetryBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sink.WriteLogs(testEntries())
+	if len(bodies) != 1 || strings.Count(bodies[0], "\n") != 2 || sink.Failed() != 0 {
+		t.Errorf("Expected a batch of 2 lines after a retry, got %q", bodies)
+	}
+
+	t.Run("Client errors are not retried", func(t *testing.T) {
+		calls.Store(0)
+		sink, _ := NewHTTPSink(&HTTPSinkOptions{URL: server.URL, RetryBackoff: time.Millisecond})
+		sink.WriteLog(testEntries()[0])
+		// 503, then 400 for the missing header
+		if calls.Load() != 2 || sink.Failed() != 1 {
+			t.Errorf("Expected 2 calls and 1 failed entry, got %d and %d", calls.Load(), sink.Failed())
+		}
+	})
+
+	t.Run("Missing URL", func(t *testing.T) {
+		if _, err := NewHTTPSink(&HTTPSinkOptions{}); err == nil {
+			t.Error("Expected error without URL")
+		}
+	})
+}
+
+func TestSyslogSink(t *testing.T) {
+	t.Run("UDP", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		sink, err := NewSyslogSink(&SyslogSinkOptions{Network: "udp", Address: conn.LocalAddr().String(), AppName: "api", Hostname: "host1", Facility: FacilityLocal0})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer sink.Close()
+
+		sink.WriteLog(testEntries()[1])
+		buf := make([]byte, 1024)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Expected a datagram, got %v", err)
+		}
+
+		pid := os.Getpid()
+		expected := `<131>1 2024-05-06T07:08:09.000000Z host1 api ` + strconv.Itoa(pid) + ` - [xlog@32473 path="a \"b\" \]" request_id="r-1"] failed`
+		if s := string(buf[:n]); s != expected {
+			t.Errorf("Expected %q, got %q", expected, s)
+		}
+	})
+
+	t.Run("TCP with octet counting", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+
+		received := make(chan string, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			length, _ := r.ReadString(' ')
+			n, _ := strconv.Atoi(strings.TrimSpace(length))
+			msg := make([]byte, n)
+			_, _ = io.ReadFull(r, msg)
+			received <- string(msg)
+		}()
+
+		sink, err := NewSyslogSink(&SyslogSinkOptions{Network: "tcp", Address: ln.Addr().String(), AppName: "api", Hostname: "host1"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer sink.Close()
+
+		sink.WriteLog(testEntries()[0])
+		select {
+		case msg := <-received:
+			if !strings.HasPrefix(msg, "<14>1 ") || !strings.HasSuffix(msg, `[xlog@32473 port="8080"] started`) {
+				t.Errorf("Unexpected message %q", msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected a message")
+		}
+	})
+
+	t.Run("Setup errors", func(t *testing.T) {
+		if _, err := NewSyslogSink(&SyslogSinkOptions{Network: "http", Address: "localhost:514"}); err == nil {
+			t.Error("Expected error for unsupported network")
+		}
+		if _, err := NewSyslogSink(&SyslogSinkOptions{Network: "unix", Address: filepath.Join(t.TempDir(), "missing.sock")}); err == nil {
+			t.Error("Expected error for unreachable server")
+		}
+	})
+}
+
+func TestRedisStreamSink(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	sink, err := NewRedisStreamSinkWithClient(client, &RedisStreamSinkOptions{Stream: "logs", MaxLen: 100})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sink.WriteLogs(testEntries())
+	messages, err := client.XRange(context.Background(), "logs", "-", "+").Result()
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("Expected 2 stream entries, got %v (%v)", messages, err)
+	}
+	if messages[1].Values["level"] != "error" {
+		t.Errorf("Expected level error, got %v", messages[1].Values["level"])
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal([]byte(messages[0].Values["entry"].(string)), &out); err != nil || out["port"] != float64(8080) {
+		t.Errorf("Unexpected entry %v (%v)", messages[0].Values["entry"], err)
+	}
+
+	if _, err := NewRedisStreamSinkWithClient(client, &RedisStreamSinkOptions{}); err == nil {
+		t.Error("Expected error without stream key")
+	}
+
+	exited := false
+	defer xlog.SetExitFunc(xlog.SetExitFunc(func(int) { exited = true }))
+	if _, err := NewRedisStreamSink(&xredis.RedisConfig{}, &RedisStreamSinkOptions{Stream: "logs"}); err == nil {
+		t.Error("Expected error without redis address")
+	}
+	if _, err := NewRedisStreamSink(nil, &RedisStreamSinkOptions{Stream: "logs"}); err == nil {
+		t.Error("Expected error without redis config")
+	}
+	if exited {
+		t.Error("Expected an error instead of a fatal exit")
+	}
+	if _, err := NewRedisStreamSinkWithClient(nil, &RedisStreamSinkOptions{Stream: "logs"}); err == nil {
+		t.Error("Expected error for a nil client")
+	}
+}
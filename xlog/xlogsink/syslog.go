@@ -0,0 +1,272 @@
+package xlogsink
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DreamvatLab/go/xerr"
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// Syslog facilities (RFC 5424 section 6.2.1)
+const (
+	FacilityUser   = 1
+	FacilityDaemon = 3
+	FacilityLocal0 = 16
+	FacilityLocal7 = 23
+)
+
+// SyslogSinkOptions holds the configuration of a SyslogSink
+type SyslogSinkOptions struct {
+	// Network is "udp", "tcp", "unix" (stream) or "unixgram"
+	Network string
+	// Address is the server address, e.g. "localhost:514" or "/dev/log"
+	Address string
+	// Facility of the messages, FacilityUser if zero
+	Facility int
+	// AppName identifies the application, the executable name if empty
+	AppName string
+	// Hostname identifies the machine, os.Hostname() if empty
+	Hostname string
+	// SDID is the structured data ID holding the fields, "xlog@32473" if empty
+	SDID string
+	// Timeout limits connecting and writing, 5 seconds if zero
+	Timeout time.Duration
+}
+
+// SyslogSink sends entries to a syslog server in the RFC 5424 format.
+// The fields and correlation IDs are written as structured data.
+// Messages are sent as datagrams over UDP and unixgram and with octet counting framing (RFC 6587) over streams.
+// A broken connection is reopened on the next entry.
+type SyslogSink struct {
+	options SyslogSinkOptions
+	header  string // " HOSTNAME APP-NAME PROCID"
+	mu      sync.Mutex
+	conn    net.Conn
+	closed  bool
+}
+
+// NewSyslogSink creates a SyslogSink and connects to the server.
+//
+// Returns an error if the network is not supported or the server cannot be reached.
+func NewSyslogSink(options *SyslogSinkOptions) (*SyslogSink, error) {
+	if options == nil || options.Address == "" {
+		return nil, xerr.New("syslog address cannot be empty")
+	}
+	switch options.Network {
+	case "udp", "tcp", "unix", "unixgram":
+	default:
+		return nil, xerr.Errorf("unsupported syslog network %q", options.Network)
+	}
+
+	r := &SyslogSink{options: *options}
+	if r.options.Facility == 0 {
+		r.options.Facility = FacilityUser
+	}
+	if r.options.AppName == "" {
+		r.options.AppName = filepath.Base(os.Args[0])
+	}
+	if r.options.Hostname == "" {
+		r.options.Hostname, _ = os.Hostname()
+	}
+	if r.options.SDID == "" {
+		r.options.SDID = "xlog@32473"
+	}
+	if r.options.Timeout <= 0 {
+		r.options.Timeout = 5 * time.Second
+	}
+	r.header = " " + headerField(r.options.Hostname, 255) + " " + headerField(r.options.AppName, 48) + " " + strconv.Itoa(os.Getpid())
+
+	if err := r.connect(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// WriteLog sends the entry
+func (o *SyslogSink) WriteLog(entry *xlog.LogEntry) {
+	msg := o.format(entry)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return
+	}
+
+	// retry once on a new connection
+	err := o.send(msg)
+	if err != nil {
+		o.close()
+		if err = o.connect(); err == nil {
+			err = o.send(msg)
+		}
+	}
+	if err != nil {
+		reportError("syslog sink", err)
+	}
+}
+
+// Close closes the connection
+func (o *SyslogSink) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	return o.close()
+}
+
+func (o *SyslogSink) connect() error {
+	conn, err := net.DialTimeout(o.options.Network, o.options.Address, o.options.Timeout)
+	if err != nil {
+		return xerr.WithMessage(err, "connect to syslog server failed")
+	}
+	o.conn = conn
+	return nil
+}
+
+func (o *SyslogSink) close() error {
+	if o.conn == nil {
+		return nil
+	}
+	err := o.conn.Close()
+	o.conn = nil
+	return err
+}
+
+// send writes a message, framed on stream connections
+func (o *SyslogSink) send(msg string) error {
+	if o.conn == nil {
+		return xerr.New("syslog connection closed")
+	}
+	if o.options.Network == "tcp" || o.options.Network == "unix" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	_ = o.conn.SetWriteDeadline(time.Now().Add(o.options.Timeout))
+	_, err := o.conn.Write([]byte(msg))
+	return err
+}
+
+// format builds the RFC 5424 message of an entry:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID name="value"...] MSG
+func (o *SyslogSink) format(entry *xlog.LogEntry) string {
+	var sb strings.Builder
+	sb.WriteByte('<')
+	sb.WriteString(strconv.Itoa(o.options.Facility*8 + severity(entry.Level)))
+	sb.WriteString(">1 ")
+	sb.WriteString(entry.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	sb.WriteString(o.header)
+	sb.WriteString(" - ")
+	sb.WriteString(o.structuredData(entry))
+	if entry.Message != "" || entry.Stack != "" {
+		sb.WriteByte(' ')
+		sb.WriteString(entry.Message)
+		if entry.Stack != "" {
+			sb.WriteByte('\n')
+			sb.WriteString(entry.Stack)
+		}
+	}
+	return sb.String()
+}
+
+// structuredData writes the correlation IDs, caller and fields as a single SD-ELEMENT, "-" if there is none
+func (o *SyslogSink) structuredData(entry *xlog.LogEntry) string {
+	params := map[string]interface{}{}
+	for key, value := range entry.Fields {
+		params[key] = value
+	}
+	for key, value := range map[string]string{
+		xlog.CallerKey:    entry.Caller,
+		xlog.RequestIDKey: entry.RequestID,
+		xlog.TraceIDKey:   entry.TraceID,
+		xlog.SpanIDKey:    entry.SpanID,
+	} {
+		if value != "" {
+			params[key] = value
+		}
+	}
+	if len(params) == 0 {
+		return "-"
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("[" + o.options.SDID)
+	for _, key := range keys {
+		sb.WriteString(" " + paramName(key) + `="`)
+		sb.WriteString(paramValue(params[key]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// severity maps an entry level to the syslog severity
+func severity(level int) int {
+	switch level {
+	case xlog.LogLevelMap[xlog.LogLevelDebug]:
+		return 7
+	case xlog.LogLevelMap[xlog.LogLevelWarn]:
+		return 4
+	case xlog.LogLevelMap[xlog.LogLevelError]:
+		return 3
+	case xlog.LogLevelMap[xlog.LogLevelFatal]:
+		return 2
+	}
+	return 6 // informational
+}
+
+// headerField keeps the printable ASCII characters of a header field, at most maxLen of them, "-" if none is left
+func headerField(s string, maxLen int) string {
+	r := strings.Map(func(c rune) rune {
+		if c < 33 || c > 126 {
+			return -1
+		}
+		return c
+	}, s)
+	if len(r) > maxLen {
+		r = r[:maxLen]
+	}
+	if r == "" {
+		return "-"
+	}
+	return r
+}
+
+// paramName removes the characters a PARAM-NAME cannot hold and truncates it to 32 characters
+func paramName(key string) string {
+	r := strings.Map(func(c rune) rune {
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' || c == ' ' {
+			return '_'
+		}
+		return c
+	}, key)
+	if len(r) > 32 {
+		r = r[:32]
+	}
+	if r == "" {
+		return "_"
+	}
+	return r
+}
+
+// paramValue formats a PARAM-VALUE, escaping '"', '\' and ']'
+func paramValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if err, ok := v.(error); ok {
+		s = err.Error()
+	}
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,107 @@
+package xlogtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// Replace installs a new Observer as the global logger until the end of the test and returns it
+func Replace(t testing.TB) *Observer {
+	t.Helper()
+
+	r := NewObserver()
+	SetLogger(t, r)
+	return r
+}
+
+// SetLogger installs logger as the global logger until the end of the test, then restores the previous one.
+// Tests replacing the global logger must not run in parallel.
+func SetLogger(t testing.TB, logger xlog.ILogger) {
+	t.Helper()
+
+	previous := xlog.SetLogger(logger)
+	t.Cleanup(func() {
+		xlog.SetLogger(previous)
+	})
+}
+
+// RequireLogged stops the test unless the global Observer installed by Replace recorded an entry of the level
+// containing substring in its message, and returns the first such entry.
+// An empty or "all" level matches every level.
+func RequireLogged(t testing.TB, level, substring string) *xlog.LogEntry {
+	t.Helper()
+
+	if logs := global(t); logs != nil {
+		return logs.RequireLogged(t, level, substring)
+	}
+	return nil
+}
+
+// RequireNotLogged stops the test if the global Observer installed by Replace recorded an entry of the level
+// containing substring in its message
+func RequireNotLogged(t testing.TB, level, substring string) {
+	t.Helper()
+
+	if logs := global(t); logs != nil {
+		logs.RequireNotLogged(t, level, substring)
+	}
+}
+
+// RequireLogged stops the test unless an entry of the level containing substring in its message was recorded,
+// and returns the first such entry
+func (o *Observer) RequireLogged(t testing.TB, level, substring string) *xlog.LogEntry {
+	t.Helper()
+
+	entries := o.Filter(level, substring)
+	if len(entries) == 0 {
+		t.Fatalf("Expected an entry%s containing %q, got %s", describeLevel(level), substring, describe(o.Entries()))
+		return nil
+	}
+	return entries[0]
+}
+
+// RequireNotLogged stops the test if an entry of the level containing substring in its message was recorded
+func (o *Observer) RequireNotLogged(t testing.TB, level, substring string) {
+	t.Helper()
+
+	if entries := o.Filter(level, substring); len(entries) > 0 {
+		t.Fatalf("Expected no entry%s containing %q, got %s", describeLevel(level), substring, describe(entries))
+	}
+}
+
+// global returns the global logger, failing the test and returning nil unless it is an Observer
+func global(t testing.TB) *Observer {
+	t.Helper()
+
+	r, ok := xlog.GetLogger().(*Observer)
+	if !ok {
+		t.Fatalf("Expected the global logger to be an Observer, got %T: call xlogtest.Replace first", xlog.GetLogger())
+		return nil
+	}
+	return r
+}
+
+// describeLevel names the level of an assertion
+func describeLevel(level string) string {
+	if level == "" || level == xlog.LogLevelAll {
+		return ""
+	}
+	return " at level " + level
+}
+
+// describe lists entries in the failure messages
+func describe(entries []*xlog.LogEntry) string {
+	if len(entries) == 0 {
+		return "no entries"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d entries:", len(entries))
+	for _, entry := range entries {
+		fmt.Fprintf(&sb, "\n\t[%s] %s", xlog.LevelName(entry.Level), entry.Message)
+	}
+	return sb.String()
+}
@@ -0,0 +1,199 @@
+// Package xlogtest records the entries logged through xlog so tests can assert on them:
+//
+//	func TestLogin(t *testing.T) {
+//		logs := xlogtest.Replace(t)
+//		login("ann", "wrong")
+//		logs.RequireLogged(t, xlog.LogLevelWarn, "invalid password")
+//	}
+//
+// An Observer is also an xlog.LogSink, to record the entries of a real logger created by xlog.Init.
+package xlogtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// Observer is an xlog.ILogger recording the entries in memory.
+// Its children created by Named, With, WithFields and WithContext record to the same entries.
+// Fatal, Fatalf and Fatalw record the entry without exiting.
+type Observer struct {
+	*recorder
+	name      string
+	fields    map[string]interface{}
+	requestID string
+	traceID   string
+	spanID    string
+}
+
+// recorder holds the entries shared by an Observer and its children
+type recorder struct {
+	mu      sync.Mutex
+	entries []*xlog.LogEntry
+}
+
+// NewObserver creates an Observer recording every level
+func NewObserver() *Observer {
+	return &Observer{recorder: new(recorder)}
+}
+
+// WriteLog records an entry, making the Observer an xlog.LogSink
+func (o *recorder) WriteLog(entry *xlog.LogEntry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = append(o.entries, entry)
+}
+
+// Entries returns the recorded entries in order
+func (o *recorder) Entries() []*xlog.LogEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]*xlog.LogEntry(nil), o.entries...)
+}
+
+// Len returns the number of recorded entries
+func (o *recorder) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// Reset forgets the recorded entries
+func (o *recorder) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = nil
+}
+
+// Filter returns the recorded entries of a level containing substring in their message.
+// An empty or "all" level matches every level.
+func (o *recorder) Filter(level, substring string) []*xlog.LogEntry {
+	var r []*xlog.LogEntry
+	for _, entry := range o.Entries() {
+		if matchLevel(entry, level) && strings.Contains(entry.Message, substring) {
+			r = append(r, entry)
+		}
+	}
+	return r
+}
+
+// matchLevel reports whether an entry has a level name of xlog.LogLevelMap
+func matchLevel(entry *xlog.LogEntry, level string) bool {
+	if level == "" || level == xlog.LogLevelAll {
+		return true
+	}
+	return xlog.LogLevelMap[strings.ToLower(level)] == entry.Level
+}
+
+func (o *Observer) Debug(v ...interface{}) {
+	o.log(xlog.LogLevelDebug, fmt.Sprint(v...), nil)
+}
+
+func (o *Observer) Debugf(format string, args ...interface{}) {
+	o.log(xlog.LogLevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+func (o *Observer) Debugw(msg string, keysAndValues ...interface{}) {
+	o.log(xlog.LogLevelDebug, msg, xlog.ToFields(keysAndValues))
+}
+
+func (o *Observer) Info(v ...interface{}) {
+	o.log(xlog.LogLevelInfo, fmt.Sprint(v...), nil)
+}
+
+func (o *Observer) Infof(format string, args ...interface{}) {
+	o.log(xlog.LogLevelInfo, fmt.Sprintf(format, args...), nil)
+}
+
+func (o *Observer) Infow(msg string, keysAndValues ...interface{}) {
+	o.log(xlog.LogLevelInfo, msg, xlog.ToFields(keysAndValues))
+}
+
+func (o *Observer) Warn(v ...interface{}) {
+	o.log(xlog.LogLevelWarn, fmt.Sprint(v...), nil)
+}
+
+func (o *Observer) Warnf(format string, args ...interface{}) {
+	o.log(xlog.LogLevelWarn, fmt.Sprintf(format, args...), nil)
+}
+
+func (o *Observer) Warnw(msg string, keysAndValues ...interface{}) {
+	o.log(xlog.LogLevelWarn, msg, xlog.ToFields(keysAndValues))
+}
+
+func (o *Observer) Error(v ...interface{}) {
+	o.log(xlog.LogLevelError, fmt.Sprint(v...), nil)
+}
+
+func (o *Observer) Errorf(format string, args ...interface{}) {
+	o.log(xlog.LogLevelError, fmt.Sprintf(format, args...), nil)
+}
+
+func (o *Observer) Errorw(msg string, keysAndValues ...interface{}) {
+	o.log(xlog.LogLevelError, msg, xlog.ToFields(keysAndValues))
+}
+
+func (o *Observer) Fatal(v ...interface{}) {
+	o.log(xlog.LogLevelFatal, fmt.Sprint(v...), nil)
+}
+
+func (o *Observer) Fatalf(format string, args ...interface{}) {
+	o.log(xlog.LogLevelFatal, fmt.Sprintf(format, args...), nil)
+}
+
+func (o *Observer) Fatalw(msg string, keysAndValues ...interface{}) {
+	o.log(xlog.LogLevelFatal, msg, xlog.ToFields(keysAndValues))
+}
+
+func (o *Observer) Named(name string) xlog.ILogger {
+	r := *o
+	if o.name != "" && name != "" {
+		r.name = o.name + "." + name
+	} else if name != "" {
+		r.name = name
+	}
+	return &r
+}
+
+func (o *Observer) With(keysAndValues ...interface{}) xlog.ILogger {
+	return o.WithFields(xlog.ToFields(keysAndValues))
+}
+
+func (o *Observer) WithFields(fields map[string]interface{}) xlog.ILogger {
+	r := *o
+	r.fields = xlog.MergeFields(o.fields, fields)
+	return &r
+}
+
+func (o *Observer) WithContext(ctx context.Context) xlog.ILogger {
+	r := *o
+	r.fields = xlog.MergeFields(o.fields, xlog.FieldsFromContext(ctx))
+	if requestID := xlog.RequestIDFromContext(ctx); requestID != "" {
+		r.requestID = requestID
+	}
+	if trace, ok := xlog.TraceFromContext(ctx); ok {
+		r.traceID, r.spanID = trace.TraceID, trace.SpanID
+	}
+	return &r
+}
+
+func (o *Observer) Finalize() {}
+
+// log records the entry of a message
+func (o *Observer) log(level, msg string, fields map[string]interface{}) {
+	o.WriteLog(&xlog.LogEntry{
+		Level:      xlog.LogLevelMap[level],
+		Time:       time.Now(),
+		LoggerName: o.name,
+		Message:    msg,
+		RequestID:  o.requestID,
+		TraceID:    o.traceID,
+		SpanID:     o.spanID,
+		Fields:     xlog.MergeFields(o.fields, fields),
+	})
+}
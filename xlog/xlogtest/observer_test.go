@@ -0,0 +1,111 @@
+package xlogtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DreamvatLab/go/xlog"
+)
+
+// fakeT records the failures of the assertions instead of stopping the test
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (o *fakeT) Helper() {}
+
+func (o *fakeT) Fatalf(format string, args ...interface{}) {
+	o.failures = append(o.failures, fmt.Sprintf(format, args...))
+}
+
+func TestObserver(t *testing.T) {
+	logs := NewObserver()
+
+	ctx := xlog.ContextWithRequestID(context.Background(), "r-1")
+	logs.Named("db").With("table", "users").WithContext(ctx).Warnw("slow query", "ms", 1200)
+	logs.Infof("served %d requests", 3)
+	logs.Fatal("stopping")
+
+	if logs.Len() != 3 {
+		t.Fatalf("Expected 3 entries, got %d", logs.Len())
+	}
+	e := logs.Entries()[0]
+	if e.Level != xlog.LogLevelMap[xlog.LogLevelWarn] || e.LoggerName != "db" || e.RequestID != "r-1" ||
+		e.Fields["table"] != "users" || e.Fields["ms"] != 1200 {
+		t.Errorf("Unexpected entry %+v", e)
+	}
+
+	if n := len(logs.Filter(xlog.LogLevelInfo, "served")); n != 1 {
+		t.Errorf("Expected 1 info entry, got %d", n)
+	}
+	if n := len(logs.Filter("", "s")); n != 3 {
+		t.Errorf("Expected 3 entries of any level, got %d", n)
+	}
+
+	logs.Infow("dangling", "id")
+	if e := logs.Entries()[3]; e.Fields[xlog.BadKey] != "id" {
+		t.Errorf("Expected the value under %s, got %v", xlog.BadKey, e.Fields)
+	}
+
+	logs.Reset()
+	if logs.Len() != 0 {
+		t.Errorf("Expected no entries after Reset, got %d", logs.Len())
+	}
+}
+
+func TestReplace(t *testing.T) {
+	previous := xlog.GetLogger()
+
+	t.Run("Global functions", func(t *testing.T) {
+		Replace(t)
+
+		xlog.Warnf("disk %d%% full", 93)
+		xlog.With("user", "ann").Infow("logged in")
+
+		if e := RequireLogged(t, xlog.LogLevelWarn, "93% full"); e.Message != "disk 93% full" {
+			t.Errorf("Unexpected entry %+v", e)
+		}
+		if e := RequireLogged(t, "", "logged in"); e.Fields["user"] != "ann" {
+			t.Errorf("Unexpected fields %v", e.Fields)
+		}
+		RequireNotLogged(t, xlog.LogLevelError, "")
+	})
+
+	if xlog.GetLogger() != previous {
+		t.Error("Expected the global logger to be restored")
+	}
+
+	t.Run("Failures", func(t *testing.T) {
+		logs := Replace(t)
+		logs.Info("hello")
+
+		ft := &fakeT{TB: t}
+		if e := RequireLogged(ft, xlog.LogLevelError, "hello"); e != nil {
+			t.Errorf("Expected no entry, got %+v", e)
+		}
+		RequireNotLogged(ft, xlog.LogLevelInfo, "hel")
+		if len(ft.failures) != 2 || !strings.Contains(ft.failures[0], "[info] hello") {
+			t.Errorf("Unexpected failures %q", ft.failures)
+		}
+	})
+
+	t.Run("Sink of a real logger", func(t *testing.T) {
+		logs := NewObserver()
+		xlog.Init(&xlog.LogConfig{Level: xlog.LogLevelWarn}, logs)
+		t.Cleanup(func() { xlog.SetLogger(previous) })
+
+		xlog.Info("filtered")
+		xlog.Error("failed")
+		logs.RequireLogged(t, xlog.LogLevelError, "failed")
+		logs.RequireNotLogged(t, "", "filtered")
+
+		ft := &fakeT{TB: t}
+		RequireLogged(ft, "", "failed")
+		if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "call xlogtest.Replace first") {
+			t.Errorf("Unexpected failures %q", ft.failures)
+		}
+	})
+}
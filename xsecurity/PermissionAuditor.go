@@ -9,6 +9,9 @@ import (
 	"github.com/DreamvatLab/go/xslice"
 )
 
+// _log is the logger of the package, its level can be set under the name "xsecurity"
+var _log = xlog.Named("xsecurity")
+
 type IPermissionAuditor interface {
 	CheckPermission(permissionID string, userRoles int64, userScopes []string) bool
 	CheckPermissionWithLevel(permissionID string, userRoles int64, userLevel int32, userScopes []string) bool
@@ -61,7 +64,7 @@ func (x *permissionAuditor) CheckPermissionWithLevel(permissionID string, userRo
 		return checkPermission(permission, userRoles, userLevel, userScopes)
 	}
 
-	xlog.Warnf("permission: %s does not exist", permissionID)
+	_log.Warnf("permission: %s does not exist", permissionID)
 	return false
 }
 
@@ -86,11 +89,11 @@ func (x *permissionAuditor) CheckRoute(area, controller, action string, userRole
 
 func (x *permissionAuditor) CheckRouteWithLevel(area, controller, action string, userRoles int64, userLevel int32, userScopes []string) bool {
 	if x.routeProvider == nil {
-		xlog.Warn("route provider is nil")
+		_log.Warn("route provider is nil")
 		return false
 	}
 	if x.permissionProvider == nil {
-		xlog.Warn("permission provider is nil")
+		_log.Warn("permission provider is nil")
 		return false
 	}
 
@@ -103,7 +106,7 @@ func (x *permissionAuditor) CheckRouteWithLevel(area, controller, action string,
 		if route, exists = x.routes[key]; !exists {
 			key = area + "__"
 			if route, exists = x.routes[key]; !exists {
-				xlog.Warnf("route: [%s,%s,%s] does not exist", area, controller, action)
+				_log.Warnf("route: [%s,%s,%s] does not exist", area, controller, action)
 				return false
 			}
 		}
@@ -112,40 +115,40 @@ func (x *permissionAuditor) CheckRouteWithLevel(area, controller, action string,
 	if permission, exists := x.permissions[route.Permission_ID]; exists {
 		r := checkPermission(permission, userRoles, userLevel, userScopes)
 		if !r {
-			xlog.Debugf("routeKey: %s_%s_%s userRoles: %d, userLevel: %d, permission: %v, userScopes: %s", area, controller, action, userRoles, userLevel, permission, strings.Join(userScopes, ","))
+			_log.Debugf("routeKey: %s_%s_%s userRoles: %d, userLevel: %d, permission: %v, userScopes: %s", area, controller, action, userRoles, userLevel, permission, strings.Join(userScopes, ","))
 		}
 		return r
 	}
 
-	xlog.Warnf("permission: %s does not exist", route.Permission_ID)
+	_log.Warnf("permission: %s does not exist", route.Permission_ID)
 	return false
 }
 
 func (x *permissionAuditor) CheckRouteKeyWithLevel(routeKey string, userRoles int64, userLevel int32, userScopes []string) bool {
 	if x.routeProvider == nil {
-		xlog.Warn("route provider is nil")
+		_log.Warn("route provider is nil")
 		return false
 	}
 	if x.permissionProvider == nil {
-		xlog.Warn("permission provider is nil")
+		_log.Warn("permission provider is nil")
 		return false
 	}
 
 	var route *xdto.Route
 	var exists bool
 	if route, exists = x.routes[routeKey]; !exists {
-		xlog.Warnf("route: [%s] does not exist", routeKey)
+		_log.Warnf("route: [%s] does not exist", routeKey)
 		return false
 	}
 
 	if permission, exists := x.permissions[route.Permission_ID]; exists {
 		r := checkPermission(permission, userRoles, userLevel, userScopes)
 		if !r {
-			xlog.Debugf("routeKey: %s, roles: %d, level: %d, permission: %v", routeKey, userRoles, userLevel, permission)
+			_log.Debugf("routeKey: %s, roles: %d, level: %d, permission: %v", routeKey, userRoles, userLevel, permission)
 		}
 		return r
 	}
 
-	xlog.Warnf("permission: %s does not exist", route.Permission_ID)
+	_log.Warnf("permission: %s does not exist", route.Permission_ID)
 	return false
 }
@@ -12,6 +12,7 @@ var r = rand.New(rand.NewSource(time.Now().UnixNano()))
 func RandomIntRange(min, max int) int {
 	if min > max {
 		xlog.Fatal("min cannot greater than max")
+		return min
 	} else if min == max {
 		return max
 	}
@@ -21,6 +22,7 @@ func RandomIntRange(min, max int) int {
 func RandomInt31Range(min, max int32) int32 {
 	if min > max {
 		xlog.Fatal("min cannot greater than max")
+		return min
 	} else if min == max {
 		return max
 	}
@@ -30,6 +32,7 @@ func RandomInt31Range(min, max int32) int32 {
 func RandomInt63Range(min, max int64) int64 {
 	if min > max {
 		xlog.Fatal("min cannot greater than max")
+		return min
 	} else if min == max {
 		return max
 	}
@@ -2,6 +2,8 @@ package xutils
 
 import (
 	"testing"
+
+	"github.com/DreamvatLab/go/xlog"
 )
 
 func TestRandomIntRange(t *testing.T) {
@@ -104,7 +106,19 @@ func TestRandomInt63Range(t *testing.T) {
 }
 
 func TestInvalidRange(t *testing.T) {
-	t.Skip("Skipping test as it requires testing Fatal behavior which is difficult in test environment")
-	// The actual implementation will call xlog.Fatal when min > max
-	// This is tested in production environment
+	var codes []int
+	defer xlog.SetExitFunc(xlog.SetExitFunc(func(code int) { codes = append(codes, code) }))
+
+	if v := RandomIntRange(5, 1); v != 5 {
+		t.Errorf("Expected 5, got %d", v)
+	}
+	if v := RandomInt31Range(5, 1); v != 5 {
+		t.Errorf("Expected 5, got %d", v)
+	}
+	if v := RandomInt63Range(5, 1); v != 5 {
+		t.Errorf("Expected 5, got %d", v)
+	}
+	if len(codes) != 3 || codes[0] != 1 {
+		t.Errorf("Expected 3 fatal exits, got %v", codes)
+	}
 }